@@ -4,14 +4,13 @@ import (
 	"net/http"
 
 	"k8s-manager/internal/handlers" // Используйте полный путь
+	"k8s-manager/internal/k8s"
 
 	"github.com/gin-gonic/gin"
-	"k8s.io/client-go/kubernetes"
-	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
-func SetupRoutes(r *gin.Engine, clientset *kubernetes.Clientset, metricsClient *metricsv.Clientset) {
-	handler := handlers.NewHandler(clientset, metricsClient)
+func SetupRoutes(r *gin.Engine, clusters *k8s.ClusterRegistry) {
+	handler := handlers.NewHandler(clusters)
 
 	// ===== UI ROUTES =====
 	r.GET("/", func(c *gin.Context) {
@@ -46,6 +45,12 @@ func SetupRoutes(r *gin.Engine, clientset *kubernetes.Clientset, metricsClient *
 		})
 	})
 
+	r.GET("/ui/statefulsets", func(c *gin.Context) {
+		c.HTML(http.StatusOK, "statefulsets.html", gin.H{
+			"Title": "StatefulSets",
+		})
+	})
+
 	r.GET("/ui/config", func(c *gin.Context) {
 		c.HTML(http.StatusOK, "config.html", gin.H{
 			"Title": "Configuration",
@@ -54,62 +59,147 @@ func SetupRoutes(r *gin.Engine, clientset *kubernetes.Clientset, metricsClient *
 
 	// ===== API ROUTES =====
 
+	// Кластер выбирается через ?cluster=name или префикс /api/clusters/:cluster
+	api := r.Group("/api", handler.ClusterMiddleware())
+	api.GET("/clusters", handler.GetClustersHandler)
+	registerAPIRoutes(api, handler)
+
+	clusterAPI := r.Group("/api/clusters/:cluster", handler.ClusterMiddleware())
+	registerAPIRoutes(clusterAPI, handler)
+}
+
+func registerAPIRoutes(api *gin.RouterGroup, handler *handlers.Handler) {
 	// Health & Info
-	api := r.Group("/api")
-	{
-		api.GET("/", handler.HomeHandler)
-		api.GET("/health", handler.HealthHandler)
-		api.GET("/test", handler.TestConnectionHandler)
-
-		// Pods
-		api.GET("/pods", handler.GetPodsHandler)
-		api.GET("/logs/:namespace/:pod", handler.GetLogsHandler)
-		api.GET("/logs/download/:namespace/:pod", handler.DownloadLogsHandler)
-		api.GET("/pod/yaml/:namespace/:pod", handler.GetPodYAMLHandler)
-		api.PUT("/pod/yaml/:namespace/:pod", handler.UpdatePodYAMLHandler)
-		api.DELETE("/pod/:namespace/:pod", handler.DeletePodHandler)
-		api.GET("/pod/details/:namespace/:pod", handler.GetPodDetailsHandler)
-
-		// Port-forwarding
-		api.GET("/portforward/sessions", handler.GetPortForwardSessionsHandler)
-		api.POST("/portforward/start", handler.StartPortForwardHandler)
-		api.POST("/portforward/stop/:id", handler.StopPortForwardHandler)
-		api.GET("/portforward/check/:port", handler.CheckPortAvailableHandler)
-
-		// Deployments
-		api.GET("/deployments", handler.GetDeploymentsHandler)
-		api.GET("/deployment/yaml/:namespace/:name", handler.GetDeploymentYAMLHandler)
-		api.PUT("/deployment/yaml/:namespace/:name", handler.UpdateDeploymentYAMLHandler)
-		api.POST("/scale/:namespace/:deployment", handler.ScaleDeploymentHandler)
-		api.POST("/restart/:namespace/:deployment", handler.RestartDeploymentHandler)
-		api.DELETE("/deployment/:namespace/:deployment", handler.DeleteDeploymentHandler)
-
-		// Applications
-		api.GET("/applications", handler.GetApplicationsHandler)
-
-		// Services
-		api.GET("/services", handler.GetServicesHandler)
-		api.GET("/service/yaml/:namespace/:name", handler.GetServiceYAMLHandler)
-
-		// ConfigMaps & Secrets
-		api.GET("/configmaps/:namespace", handler.GetConfigMapsHandler)
-		api.GET("/configmap/yaml/:namespace/:name", handler.GetConfigMapYAMLHandler)
-		api.GET("/secrets/:namespace", handler.GetSecretsHandler)
-
-		// Namespaces & Nodes
-		api.GET("/namespaces", handler.GetNamespacesHandler)
-		api.GET("/nodes", handler.GetNodesHandler)
-
-		// Metrics API
-		api.GET("/metrics/pods/:namespace", handler.GetPodMetricsHandler)
-		api.GET("/metrics/pod/:namespace/:pod", handler.GetSinglePodMetricsHandler)
-		api.GET("/metrics/all-pods", handler.GetAllPodsMetricsHandler)
-		api.GET("/metrics/nodes", handler.GetNodeMetricsHandler)
-
-		// Real-time logs API
-api.GET("/logs/stream/:namespace/:pod", handler.StartLogStreamHandler)
-api.GET("/logs/streams", handler.GetLogStreamsHandler)
-api.DELETE("/logs/stream/:id", handler.StopLogStreamHandler)
-api.GET("/watch/pods", handler.WatchPodsHandler)
-	}
+	api.GET("/", handler.HomeHandler)
+	api.GET("/health", handler.HealthHandler)
+	api.GET("/test", handler.TestConnectionHandler)
+
+	// Pods
+	api.GET("/pods", handler.GetPodsHandler)
+	api.GET("/logs/:namespace/:pod", handler.GetLogsHandler)
+	api.GET("/logs/download/:namespace/:pod", handler.DownloadLogsHandler)
+	api.GET("/pod/files/:namespace/:pod", handler.DownloadPodFileHandler)
+	api.POST("/pod/files/:namespace/:pod", handler.UploadPodFileHandler)
+	api.GET("/pod/yaml/:namespace/:pod", handler.GetPodYAMLHandler)
+	api.PUT("/pod/yaml/:namespace/:pod", handler.UpdatePodYAMLHandler)
+	api.DELETE("/pod/:namespace/:pod", handler.DeletePodHandler)
+	api.GET("/pod/details/:namespace/:pod", handler.GetPodDetailsHandler)
+	api.GET("/pod/owners/:namespace/:pod", handler.GetPodOwnersHandler)
+	api.POST("/pod/debug/:namespace/:pod", handler.CreateDebugContainerHandler)
+	api.GET("/pod/debug/attach/:namespace/:pod", handler.AttachDebugContainerHandler)
+
+	// Port-forwarding
+	api.GET("/portforward/sessions", handler.GetPortForwardSessionsHandler)
+	api.POST("/portforward/start", handler.StartPortForwardHandler)
+	api.POST("/portforward/stop/:id", handler.StopPortForwardHandler)
+	api.GET("/portforward/check/:port", handler.CheckPortAvailableHandler)
+
+	// Exec (WebSocket terminal)
+	api.GET("/exec/:namespace/:pod", handler.ExecHandler)
+	api.GET("/exec/sessions", handler.GetExecSessionsHandler)
+	api.POST("/exec/stop/:id", handler.StopExecSessionHandler)
+
+	// Deployments
+	api.GET("/deployments", handler.GetDeploymentsHandler)
+	api.GET("/deployment/yaml/:namespace/:name", handler.GetDeploymentYAMLHandler)
+	api.GET("/deployment/details/:namespace/:name", handler.GetDeploymentDetailsHandler)
+	api.PUT("/deployment/yaml/:namespace/:name", handler.UpdateDeploymentYAMLHandler)
+	api.POST("/scale/:namespace/:deployment", handler.ScaleDeploymentHandler)
+	api.POST("/restart/:namespace/:deployment", handler.RestartDeploymentHandler)
+	api.DELETE("/deployment/:namespace/:deployment", handler.DeleteDeploymentHandler)
+	api.GET("/deployment/history/:namespace/:name", handler.GetDeploymentHistoryHandler)
+	api.POST("/deployment/rollback/:namespace/:name", handler.RollbackDeploymentHandler)
+	api.POST("/deployment/pause/:namespace/:name", handler.PauseDeploymentHandler)
+	api.POST("/deployment/resume/:namespace/:name", handler.ResumeDeploymentHandler)
+	api.GET("/deployment/rollout/:namespace/:name", handler.WatchRolloutStatusHandler)
+
+	// StatefulSets
+	api.GET("/statefulsets", handler.GetStatefulSetsHandler)
+	api.GET("/statefulset/yaml/:namespace/:name", handler.GetStatefulSetYAMLHandler)
+	api.PUT("/statefulset/yaml/:namespace/:name", handler.UpdateStatefulSetYAMLHandler)
+	api.POST("/statefulset/scale/:namespace/:name", handler.ScaleStatefulSetHandler)
+	api.POST("/statefulset/restart/:namespace/:name", handler.RestartStatefulSetHandler)
+	api.POST("/statefulset/partition/:namespace/:name", handler.UpdateStatefulSetPartitionHandler)
+	api.DELETE("/statefulset/:namespace/:name", handler.DeleteStatefulSetHandler)
+
+	// DaemonSets & ReplicaSets
+	api.GET("/daemonsets", handler.GetDaemonSetsHandler)
+	api.GET("/daemonset/yaml/:namespace/:name", handler.GetDaemonSetYAMLHandler)
+	api.GET("/replicasets", handler.GetReplicaSetsHandler)
+	api.GET("/replicaset/yaml/:namespace/:name", handler.GetReplicaSetYAMLHandler)
+
+	// Jobs & CronJobs
+	api.GET("/jobs", handler.GetJobsHandler)
+	api.GET("/job/pods/:namespace/:name", handler.GetJobPodsHandler)
+	api.DELETE("/job/:namespace/:name", handler.DeleteJobHandler)
+	api.DELETE("/jobs/finished/:namespace", handler.DeleteFinishedJobsHandler)
+	api.GET("/cronjobs", handler.GetCronJobsHandler)
+	api.GET("/cronjob/history/:namespace/:name", handler.GetCronJobHistoryHandler)
+	api.POST("/cronjob/trigger/:namespace/:name", handler.TriggerCronJobHandler)
+	api.POST("/cronjob/suspend/:namespace/:name", handler.SuspendCronJobHandler)
+	api.POST("/cronjob/resume/:namespace/:name", handler.ResumeCronJobHandler)
+
+	// Applications
+	api.GET("/applications", handler.GetApplicationsHandler)
+
+	// Services
+	api.GET("/services", handler.GetServicesHandler)
+	api.GET("/service/yaml/:namespace/:name", handler.GetServiceYAMLHandler)
+
+	// Ingress & Gateway API
+	api.GET("/ingresses", handler.GetIngressesHandler)
+	api.GET("/ingressclasses", handler.GetIngressClassesHandler)
+	api.POST("/ingress/probe/:namespace/:name", handler.ProbeIngressHandler)
+	api.GET("/gateways", handler.GetGatewaysHandler)
+	api.GET("/httproutes", handler.GetHTTPRoutesHandler)
+	api.POST("/httproute/probe/:namespace/:name", handler.ProbeHTTPRouteHandler)
+
+	// ConfigMaps & Secrets
+	api.GET("/configmaps/:namespace", handler.GetConfigMapsHandler)
+	api.GET("/configmap/yaml/:namespace/:name", handler.GetConfigMapYAMLHandler)
+	api.GET("/secrets/:namespace", handler.GetSecretsHandler)
+
+	// Namespaces & Nodes
+	api.GET("/namespaces", handler.GetNamespacesHandler)
+	api.GET("/nodes", handler.GetNodesHandler)
+
+	// Manifests (server-side apply)
+	api.POST("/diff", handler.DiffYAMLHandler)
+	api.POST("/apply", handler.ApplyManifestHandler)
+
+	// Generic resources (discovery, any kind including CRDs; group "core" for v1)
+	api.GET("/resources", handler.GetAPIResourcesHandler)
+	api.GET("/resources/:group/:version/:resource", handler.ListResourcesHandler)
+	api.GET("/resources/:group/:version/:resource/:name", handler.GetResourceYAMLHandler)
+	api.PUT("/resources/:group/:version/:resource/:name", handler.UpdateResourceYAMLHandler)
+	api.DELETE("/resources/:group/:version/:resource/:name", handler.DeleteResourceHandler)
+
+	// Metrics API
+	api.GET("/metrics/pods/:namespace", handler.GetPodMetricsHandler)
+	api.GET("/metrics/pod/:namespace/:pod", handler.GetSinglePodMetricsHandler)
+	api.GET("/metrics/all-pods", handler.GetAllPodsMetricsHandler)
+	api.GET("/metrics/nodes", handler.GetNodeMetricsHandler)
+
+	// Real-time logs API
+	api.GET("/logs/stream/:namespace/:pod", handler.StartLogStreamHandler)
+	api.GET("/logs/streams", handler.GetLogStreamsHandler)
+	api.GET("/logs/aggregate/:namespace", handler.StartAggregatedLogStreamHandler)
+	api.DELETE("/logs/stream/:id", handler.StopLogStreamHandler)
+
+	// Events API
+	api.GET("/events", handler.GetEventsHandler)
+	api.GET("/events/stream", handler.StreamEventsHandler)
+
+	// Watch hub (WebSocket / SSE)
+	api.GET("/watch", handler.GetWatchFeedsHandler)
+	api.GET("/watch/:resource", handler.WatchResourceHandler)
+
+	// Log capture (background, to disk)
+	api.POST("/capture/start", handler.StartCaptureHandler)
+	api.GET("/capture/sessions", handler.GetCaptureSessionsHandler)
+	api.POST("/capture/stop/:id", handler.StopCaptureHandler)
+	api.DELETE("/capture/:id", handler.DeleteCaptureHandler)
+	api.GET("/capture/files", handler.GetCaptureFilesHandler)
+	api.GET("/capture/search", handler.SearchCaptureHandler)
+	api.GET("/capture/download", handler.DownloadCaptureHandler)
 }
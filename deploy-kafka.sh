@@ -1,5 +1,17 @@
 #!/bin/bash
 
+# Если задан K8S_MANAGER_URL (например http://localhost:8080), манифесты применяются
+# через k8s-manager (POST /api/apply, server-side apply), иначе через kubectl
+apply_manifest() {
+    if [ -n "$K8S_MANAGER_URL" ]; then
+        curl -sS --fail-with-body -X POST -H "Content-Type: application/yaml" \
+            --data-binary "@$1" "$K8S_MANAGER_URL/api/apply?namespace=market" || exit 1
+        echo ""
+    else
+        kubectl apply -f "$1"
+    fi
+}
+
 echo "🚀 Развертывание ZooKeeper и Kafka в namespace market..."
 
 echo "🗑️  Удаление старой Kafka (если есть)..."
@@ -9,14 +21,14 @@ kubectl delete -n market service zookeeper 2>/dev/null || true
 kubectl delete -n market statefulset zookeeper 2>/dev/null || true
 
 echo "📦 Развертывание ZooKeeper..."
-kubectl apply -f zookeeper.yaml
+apply_manifest zookeeper.yaml
 
 echo "⏳ Ожидание запуска ZooKeeper (30 секунд)..."
 sleep 30
 kubectl wait --namespace market --for=condition=ready pod -l app=zookeeper --timeout=120s
 
 echo "📦 Развертывание Kafka..."
-kubectl apply -f kafka-with-zookeeper.yaml
+apply_manifest kafka-with-zookeeper.yaml
 
 echo "⏳ Ожидание запуска Kafka (40 секунд)..."
 sleep 40
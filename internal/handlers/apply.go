@@ -0,0 +1,359 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"k8s-manager/internal/k8s"
+	"k8s-manager/internal/utils"
+)
+
+// applyOptions - ?dryRun=All (только валидация на сервере) и ?force=true (забрать чужие поля)
+func applyOptions(c *gin.Context) (k8s.ApplyOptions, error) {
+	var opts k8s.ApplyOptions
+
+	switch strings.ToLower(c.Query("dryRun")) {
+	case "", "false", "none":
+	case "all", "true":
+		opts.DryRun = true
+	default:
+		return opts, fmt.Errorf("invalid dryRun value %q, expected All", c.Query("dryRun"))
+	}
+
+	if forceStr := c.Query("force"); forceStr != "" {
+		force, err := strconv.ParseBool(forceStr)
+		if err != nil {
+			return opts, fmt.Errorf("invalid force value %q", forceStr)
+		}
+		opts.Force = force
+	}
+
+	return opts, nil
+}
+
+// apiError - ответ на ошибку API-сервера (apply, dynamic client). Конфликт владения полями - 409 со списком менеджеров.
+func apiError(c *gin.Context, err error) {
+	if conflicts := k8s.ApplyConflicts(err); len(conflicts) > 0 {
+		managers := []string{}
+		seen := map[string]bool{}
+		for _, conflict := range conflicts {
+			if conflict.Manager != "" && !seen[conflict.Manager] {
+				seen[conflict.Manager] = true
+				managers = append(managers, conflict.Manager)
+			}
+		}
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     err.Error(),
+			"conflicts": conflicts,
+			"managers":  managers,
+			"hint":      "Fields are managed by another field manager. Retry with force=true to take ownership.",
+		})
+		return
+	}
+
+	status := http.StatusInternalServerError
+	switch {
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+		status = http.StatusUnprocessableEntity
+	case apierrors.IsConflict(err), apierrors.IsAlreadyExists(err):
+		status = http.StatusConflict
+	case apierrors.IsForbidden(err):
+		status = http.StatusForbidden
+	case apierrors.IsNotFound(err), meta.IsNoMatchError(err):
+		status = http.StatusNotFound
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// objectYAML - объект после apply в YAML без managedFields
+func objectYAML(obj *unstructured.Unstructured) (string, error) {
+	clean := obj.DeepCopy()
+	unstructured.RemoveNestedField(clean.Object, "metadata", "managedFields")
+
+	data, err := sigsyaml.Marshal(clean.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// applyEditedYAML - server-side apply YAML из редактора для объекта kind namespace/name.
+// Группа и kind из YAML должны совпадать с kind: apply идет по apiVersion из YAML, а не по URL.
+// Возвращает тело ответа или nil, если ответ с ошибкой уже записан.
+func (h *Handler) applyEditedYAML(c *gin.Context, kind schema.GroupKind, namespace, name string) gin.H {
+	var request struct {
+		YAML string `json:"yaml"`
+	}
+
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil
+	}
+
+	opts, err := applyOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil
+	}
+
+	cluster := h.cluster(c)
+	if cluster == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return nil
+	}
+
+	obj, err := k8s.DecodeObject([]byte(request.YAML))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid YAML: " + err.Error()})
+		return nil
+	}
+
+	if gk := obj.GroupVersionKind().GroupKind(); gk != kind {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Kind mismatch: expected %s, got %s", kind, gk)})
+		return nil
+	}
+	if obj.GetName() != name || obj.GetNamespace() != namespace {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name or namespace mismatch"})
+		return nil
+	}
+
+	result, err := cluster.Apply(c.Request.Context(), obj, namespace, opts)
+	if err != nil {
+		apiError(c, err)
+		return nil
+	}
+
+	yamlData, err := objectYAML(result.Object)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil
+	}
+
+	message := fmt.Sprintf("%s %s", kind.Kind, result.Action)
+	if opts.DryRun {
+		message += " (dry run, nothing saved)"
+	}
+
+	return gin.H{
+		"message":      message,
+		"action":       result.Action,
+		"dryRun":       opts.DryRun,
+		"fieldManager": k8s.FieldManager,
+		"namespace":    namespace,
+		"yaml":         yamlData,
+	}
+}
+
+// DiffYAMLHandler - что изменит сохранение YAML из редактора: структурный и unified diff с живым объектом.
+// Отредактированный объект проходит dry-run apply, поэтому значения по умолчанию подставляет сервер
+// и в diff не попадают; status, managedFields и прочие серверные поля не сравниваются.
+func (h *Handler) DiffYAMLHandler(c *gin.Context) {
+	var request struct {
+		YAML      string `json:"yaml"`
+		Namespace string `json:"namespace"`
+	}
+
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.Namespace == "" {
+		request.Namespace = "default"
+	}
+
+	cluster := h.cluster(c)
+	if cluster == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	obj, err := k8s.DecodeObject([]byte(request.YAML))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid YAML: " + err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// Конфликт владения не мешает предпросмотру: показываем результат с force и сами конфликты
+	result, err := cluster.Apply(ctx, obj.DeepCopy(), request.Namespace, k8s.ApplyOptions{DryRun: true})
+	conflicts := k8s.ApplyConflicts(err)
+	if len(conflicts) > 0 {
+		result, err = cluster.Apply(ctx, obj.DeepCopy(), request.Namespace, k8s.ApplyOptions{DryRun: true, Force: true})
+	}
+	if err != nil {
+		apiError(c, err)
+		return
+	}
+
+	live := k8s.DiffView(result.Live)
+	edited := k8s.DiffView(result.Object)
+
+	liveYAML, err := sigsyaml.Marshal(live)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	editedYAML, err := sigsyaml.Marshal(edited)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if result.Live == nil {
+		liveYAML = nil
+	}
+
+	changes := utils.DiffObjects(live, edited)
+
+	if conflicts == nil {
+		conflicts = []k8s.ApplyConflict{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"kind":      result.Object.GetKind(),
+		"name":      result.Object.GetName(),
+		"namespace": result.Object.GetNamespace(),
+		"exists":    result.Live != nil,
+		"action":    result.Action,
+		"changed":   len(changes) > 0,
+		"changes":   changes,
+		"diff":      utils.UnifiedDiff(string(liveYAML), string(editedYAML), "live", "edited"),
+		"conflicts": conflicts,
+	})
+}
+
+// maxManifestSize - предел тела POST /apply (для multipart - всех файлов вместе)
+const maxManifestSize = 10 << 20
+
+// errManifestTooLarge - манифест больше maxManifestSize (ответ 413)
+var errManifestTooLarge = fmt.Errorf("manifest exceeds %d MiB", maxManifestSize>>20)
+
+// readManifest - YAML манифеста: multipart (одно или несколько полей file), JSON {yaml} или сырое тело
+func readManifest(c *gin.Context) ([]byte, error) {
+	// Предел на весь запрос: сумма файлов multipart, JSON и сырое тело
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxManifestSize)
+
+	switch c.ContentType() {
+	case "multipart/form-data":
+		form, err := c.MultipartForm()
+		if err != nil {
+			return nil, err
+		}
+		files := form.File["file"]
+		if len(files) == 0 {
+			return nil, fmt.Errorf("file is required")
+		}
+
+		var manifest bytes.Buffer
+		for _, fileHeader := range files {
+			if int64(manifest.Len())+fileHeader.Size > maxManifestSize {
+				return nil, errManifestTooLarge
+			}
+			src, err := fileHeader.Open()
+			if err != nil {
+				return nil, err
+			}
+			_, err = io.Copy(&manifest, src)
+			src.Close()
+			if err != nil {
+				return nil, err
+			}
+			manifest.WriteString("\n---\n")
+		}
+		return manifest.Bytes(), nil
+
+	case "application/json":
+		var request struct {
+			YAML string `json:"yaml"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			return nil, err
+		}
+		return []byte(request.YAML), nil
+	}
+
+	return io.ReadAll(c.Request.Body)
+}
+
+// manifestTooLarge - запрос или файлы манифеста больше maxManifestSize
+func manifestTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.Is(err, errManifestTooLarge) || errors.As(err, &maxBytesErr)
+}
+
+// ApplyManifestHandler - kubectl apply -f для многодокументного YAML через server-side apply.
+// Namespace и CRD применяются первыми; результат по каждому объекту: created, configured, unchanged или error.
+// ?namespace=default (для объектов без namespace)&dryRun=All&force=true
+func (h *Handler) ApplyManifestHandler(c *gin.Context) {
+	namespace := c.DefaultQuery("namespace", "default")
+
+	opts, err := applyOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	data, err := readManifest(c)
+	if manifestTooLarge(err) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": errManifestTooLarge.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	objects, err := k8s.DecodeManifest(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid YAML: " + err.Error()})
+		return
+	}
+	if len(objects) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Manifest contains no objects"})
+		return
+	}
+
+	cluster := h.cluster(c)
+	if cluster == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	results := cluster.ApplyManifest(c.Request.Context(), objects, namespace, opts)
+
+	summary := map[string]int{
+		k8s.ApplyCreated:    0,
+		k8s.ApplyConfigured: 0,
+		k8s.ApplyUnchanged:  0,
+		k8s.ApplyFailed:     0,
+	}
+	for _, result := range results {
+		summary[result.Action]++
+	}
+
+	// Частичный успех - 422, чтобы скрипты с curl --fail видели ошибку
+	status := http.StatusOK
+	if summary[k8s.ApplyFailed] > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+
+	c.JSON(status, gin.H{
+		"dryRun":       opts.DryRun,
+		"fieldManager": k8s.FieldManager,
+		"total":        len(results),
+		"summary":      summary,
+		"results":      results,
+	})
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// attachmentWriter - отдает поток как вложение; заголовки уходят с первой записью
+type attachmentWriter struct {
+	c           *gin.Context
+	filename    string
+	contentType string
+	started     bool
+}
+
+func (w *attachmentWriter) start() {
+	if w.started {
+		return
+	}
+	w.started = true
+	w.c.Header("Content-Type", w.contentType)
+	w.c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", w.filename))
+	w.c.Status(http.StatusOK)
+	w.c.Writer.WriteHeaderNow()
+}
+
+func (w *attachmentWriter) Write(p []byte) (int, error) {
+	w.start()
+	n, err := w.c.Writer.Write(p)
+	w.c.Writer.Flush()
+	return n, err
+}
+
+// containerPath - очищенный абсолютный путь в контейнере (корень не допускается)
+func containerPath(p string) (string, bool) {
+	if !strings.HasPrefix(p, "/") {
+		return "", false
+	}
+	p = path.Clean(p)
+	if p == "/" {
+		return "", false
+	}
+	return p, true
+}
+
+// tarToZip - потоковая перепаковка tar в zip без буферизации файлов в памяти
+func tarToZip(r io.Reader, w io.Writer) error {
+	tr := tar.NewReader(r)
+	zw := zip.NewWriter(w)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		// Защита от "../" в именах из архива
+		name := strings.TrimPrefix(path.Clean("/"+hdr.Name), "/")
+		if name == "" {
+			continue
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			header, err := zip.FileInfoHeader(hdr.FileInfo())
+			if err != nil {
+				return err
+			}
+			header.Name = name + "/"
+			if _, err := zw.CreateHeader(header); err != nil {
+				return err
+			}
+
+		case tar.TypeReg:
+			header, err := zip.FileInfoHeader(hdr.FileInfo())
+			if err != nil {
+				return err
+			}
+			header.Name = name
+			header.Method = zip.Deflate
+
+			fw, err := zw.CreateHeader(header)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(fw, tr); err != nil {
+				return err
+			}
+
+		default:
+			// Симлинки и спецфайлы в zip не переносим
+			continue
+		}
+	}
+
+	return zw.Close()
+}
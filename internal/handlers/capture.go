@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"bufio"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+
+	"k8s-manager/internal/k8s"
+)
+
+const defaultCaptureSearchLimit = 200
+
+// CaptureRequest - что захватывать: под, селектор или workload
+type CaptureRequest struct {
+	Namespace   string `json:"namespace" binding:"required"`
+	Pod         string `json:"pod"`
+	Selector    string `json:"selector"`
+	Deployment  string `json:"deployment"`
+	StatefulSet string `json:"statefulset"`
+	Container   string `json:"container"` // regex по имени контейнера
+	Tail        *int64 `json:"tail"`      // строк истории при подключении (по умолчанию 100)
+}
+
+// StartCaptureHandler - запуск фонового захвата логов на диск
+func (h *Handler) StartCaptureHandler(c *gin.Context) {
+	var req CaptureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// namespace входит в имя каталога сессии
+	if !isValidNamespace(req.Namespace) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid namespace"})
+		return
+	}
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	var containerFilter func(string) bool
+	if req.Container != "" {
+		re, err := regexp.Compile(req.Container)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container regex: " + err.Error()})
+			return
+		}
+		containerFilter = re.MatchString
+	}
+
+	// Один под - по имени, иначе селектор
+	selector := ""
+	fieldSelector := ""
+	if req.Pod != "" {
+		if !isValidPodName(req.Pod) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pod name"})
+			return
+		}
+		fieldSelector = fields.OneTermEqualSelector("metadata.name", req.Pod).String()
+	} else {
+		sel, err := workloadSelector(c.Request.Context(), clientset, req.Namespace, req.Selector, req.Deployment, req.StatefulSet)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		selector = sel.String()
+	}
+
+	tail := int64(100)
+	if req.Tail != nil && *req.Tail >= 0 {
+		tail = *req.Tail
+	}
+
+	cluster := h.cluster(c)
+	manager := k8s.GetCaptureManager()
+
+	target := selector
+	if req.Pod != "" {
+		target = "pod=" + req.Pod
+	}
+	session := manager.NewCaptureSession(cluster.Name, req.Namespace, target, req.Container)
+	manager.AddSession(session)
+
+	follower := &k8s.PodLogFollower{
+		Clientset:       cluster.Clientset,
+		Namespace:       req.Namespace,
+		Selector:        selector,
+		FieldSelector:   fieldSelector,
+		ContainerFilter: containerFilter,
+		Options:         corev1.PodLogOptions{TailLines: &tail},
+	}
+
+	// Фоновая горутина переживает запрос и работает без подключенного браузера
+	go k8s.StartCapture(session, follower)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Log capture started",
+		"session": captureSessionInfo(session),
+		"files":   h.apiLink(c, "/capture/files") + "&session=" + session.ID,
+	})
+}
+
+// GetCaptureSessionsHandler - сессии захвата (включая остановленные, пока их не удалили)
+func (h *Handler) GetCaptureSessionsHandler(c *gin.Context) {
+	sessions := k8s.GetCaptureManager().GetSessions()
+
+	result := []gin.H{}
+	for _, session := range sessions {
+		result = append(result, captureSessionInfo(session))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":    len(result),
+		"sessions": result,
+		"dir":      k8s.GetCaptureManager().Config().Dir,
+	})
+}
+
+// StopCaptureHandler - остановка захвата; файлы остаются на диске
+func (h *Handler) StopCaptureHandler(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	if !k8s.GetCaptureManager().StopSession(sessionID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Capture session not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Log capture stopped",
+		"session": sessionID,
+	})
+}
+
+// DeleteCaptureHandler - удаление сессии захвата из списка (работающая сначала останавливается); файлы остаются на диске
+func (h *Handler) DeleteCaptureHandler(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	if !k8s.GetCaptureManager().DeleteSession(sessionID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Capture session not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Log capture session deleted",
+		"session": sessionID,
+	})
+}
+
+// GetCaptureFilesHandler - список захваченных файлов и архивов (?session=&pod=)
+func (h *Handler) GetCaptureFilesHandler(c *gin.Context) {
+	files, err := k8s.GetCaptureManager().ListCaptureFiles(c.Query("session"), c.Query("pod"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := []gin.H{}
+	for _, file := range files {
+		result = append(result, gin.H{
+			"path":       file.Path,
+			"session":    file.Session,
+			"pod":        file.Pod,
+			"container":  file.Container,
+			"size":       file.Size,
+			"modTime":    file.ModTime.Format(time.RFC3339),
+			"compressed": file.Compressed,
+			"download":   h.apiLink(c, "/capture/download") + "&file=" + file.Path,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count": len(result),
+		"files": result,
+	})
+}
+
+// SearchCaptureHandler - поиск по захваченным логам, включая сжатые архивы
+// ?q=regex&session=&pod=&limit=200
+func (h *Handler) SearchCaptureHandler(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+
+	re, err := regexp.Compile(query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid regex: " + err.Error()})
+		return
+	}
+
+	limit := defaultCaptureSearchLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	manager := k8s.GetCaptureManager()
+	files, err := manager.ListCaptureFiles(c.Query("session"), c.Query("pod"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	matches := []gin.H{}
+	truncated := false
+
+search:
+	for _, file := range files {
+		if c.Request.Context().Err() != nil {
+			return
+		}
+
+		path, ok := manager.ResolveCaptureFile(file.Path)
+		if !ok {
+			continue
+		}
+
+		reader, err := k8s.OpenCaptureFile(path)
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(reader)
+		const maxCapacity = 1024 * 1024 // 1MB
+		scanner.Buffer(make([]byte, 64*1024), maxCapacity)
+
+		lineNo := 0
+		for scanner.Scan() {
+			lineNo++
+			if !re.MatchString(scanner.Text()) {
+				continue
+			}
+			if len(matches) >= limit {
+				truncated = true
+				reader.Close()
+				break search
+			}
+			matches = append(matches, gin.H{
+				"file":      file.Path,
+				"pod":       file.Pod,
+				"container": file.Container,
+				"line":      lineNo,
+				"text":      scanner.Text(),
+			})
+		}
+		reader.Close()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":     query,
+		"count":     len(matches),
+		"truncated": truncated,
+		"matches":   matches,
+	})
+}
+
+// DownloadCaptureHandler - скачивание файла захвата (?file=<path из списка>)
+func (h *Handler) DownloadCaptureHandler(c *gin.Context) {
+	path, ok := k8s.GetCaptureManager().ResolveCaptureFile(c.Query("file"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Capture file not found"})
+		return
+	}
+
+	c.FileAttachment(path, filepath.Base(path))
+}
+
+func captureSessionInfo(session *k8s.CaptureSession) gin.H {
+	state := session.State()
+	return gin.H{
+		"id":        session.ID,
+		"cluster":   session.Cluster,
+		"namespace": session.Namespace,
+		"selector":  session.Selector,
+		"container": session.Container,
+		"dir":       session.Dir,
+		"status":    state.Status,
+		"error":     state.Error,
+		"lines":     session.Lines(),
+		"bytes":     session.Bytes(),
+		"rotations": session.Rotations(),
+		"createdAt": session.CreatedAt.Format(time.RFC3339),
+	}
+}
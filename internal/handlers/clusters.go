@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetClustersHandler - список кластеров (контекстов) из kubeconfig
+func (h *Handler) GetClustersHandler(c *gin.Context) {
+	defaultName := h.clusters.DefaultName()
+
+	var result []gin.H
+	for _, cluster := range h.clusters.List() {
+		result = append(result, gin.H{
+			"name":      cluster.Name,
+			"context":   cluster.Context,
+			"server":    cluster.Server,
+			"namespace": cluster.Namespace,
+			"default":   cluster.Name == defaultName,
+			"metrics":   cluster.MetricsClient != nil,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"default":  defaultName,
+		"count":    len(result),
+		"clusters": result,
+	})
+}
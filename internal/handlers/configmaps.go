@@ -12,12 +12,13 @@ import (
 func (h *Handler) GetConfigMapsHandler(c *gin.Context) {
 	namespace := c.Param("namespace")
 
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
 		return
 	}
 
-	configmaps, err := h.clientset.CoreV1().ConfigMaps(namespace).List(c.Request.Context(), metav1.ListOptions{})
+	configmaps, err := clientset.CoreV1().ConfigMaps(namespace).List(c.Request.Context(), metav1.ListOptions{})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -44,12 +45,13 @@ func (h *Handler) GetConfigMapYAMLHandler(c *gin.Context) {
 	namespace := c.Param("namespace")
 	name := c.Param("name")
 
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
 		return
 	}
 
-	configmap, err := h.clientset.CoreV1().ConfigMaps(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
+	configmap, err := clientset.CoreV1().ConfigMaps(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
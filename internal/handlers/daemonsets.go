@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+func (h *Handler) GetDaemonSetsHandler(c *gin.Context) {
+	namespace := c.DefaultQuery("namespace", "market")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	daemonSets, err := h.resources(c).ListDaemonSets(c.Request.Context(), namespace, liveRead(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var result []gin.H
+	for _, ds := range daemonSets.Items {
+		result = append(result, gin.H{
+			"name":          ds.Name,
+			"namespace":     ds.Namespace,
+			"ready":         fmt.Sprintf("%d/%d", ds.Status.NumberReady, ds.Status.DesiredNumberScheduled),
+			"ready_count":   ds.Status.NumberReady,
+			"total_count":   ds.Status.DesiredNumberScheduled,
+			"current":       ds.Status.CurrentNumberScheduled,
+			"up_to_date":    ds.Status.UpdatedNumberScheduled,
+			"available":     ds.Status.NumberAvailable,
+			"misscheduled":  ds.Status.NumberMisscheduled,
+			"node_selector": ds.Spec.Template.Spec.NodeSelector,
+			"age":           time.Since(ds.CreationTimestamp.Time).Round(time.Second).String(),
+			"labels":        ds.Labels,
+			"strategy":      string(ds.Spec.UpdateStrategy.Type),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"namespace":  namespace,
+		"count":      len(daemonSets.Items),
+		"daemonsets": result,
+		"source":     readSource(c),
+	})
+}
+
+func (h *Handler) GetDaemonSetYAMLHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	ds, err := clientset.AppsV1().DaemonSets(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ds.ManagedFields = nil
+	ds.TypeMeta = metav1.TypeMeta{
+		APIVersion: "apps/v1",
+		Kind:       "DaemonSet",
+	}
+
+	yamlData, err := sigsyaml.Marshal(ds)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"name":      name,
+		"namespace": namespace,
+		"yaml":      string(yamlData),
+	})
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"k8s-manager/internal/k8s"
+)
+
+const (
+	defaultDebugImage   = "busybox:1.36"
+	debugStartTimeout   = 2 * time.Minute
+	debugStatusInterval = time.Second
+)
+
+// DebugContainerRequest - параметры ephemeral debug-контейнера
+type DebugContainerRequest struct {
+	Image   string   `json:"image"`
+	Target  string   `json:"target"`
+	Name    string   `json:"name"`
+	Command []string `json:"command"`
+}
+
+// CreateDebugContainerHandler - добавляет в под ephemeral-контейнер (kubectl debug -it --target)
+func (h *Handler) CreateDebugContainerHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	podName := c.Param("pod")
+
+	var req DebugContainerRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.Image == "" {
+		req.Image = defaultDebugImage
+	}
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	pod, err := clientset.CoreV1().Pods(namespace).Get(c.Request.Context(), podName, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if pod.Status.Phase != corev1.PodRunning {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Pod is %s, debug containers require a running pod", pod.Status.Phase)})
+		return
+	}
+
+	// Target - контейнер, с которым делим пространство имен процессов
+	if req.Target == "" {
+		req.Target = defaultContainer(pod)
+	} else if !hasContainer(pod, req.Target) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Target container not found in pod: " + req.Target})
+		return
+	}
+
+	if req.Name == "" {
+		req.Name = "debugger-" + rand.String(5)
+	} else if hasContainer(pod, req.Name) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Container already exists in pod: " + req.Name})
+		return
+	}
+
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:                     req.Name,
+			Image:                    req.Image,
+			Command:                  req.Command,
+			ImagePullPolicy:          corev1.PullIfNotPresent,
+			Stdin:                    true,
+			TTY:                      true,
+			TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		},
+		TargetContainerName: req.Target,
+	})
+
+	_, err = clientset.CoreV1().Pods(namespace).UpdateEphemeralContainers(c.Request.Context(), podName, pod, metav1.UpdateOptions{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Printf("Debug container %s (%s) added to pod %s/%s, target: %s", req.Name, req.Image, namespace, podName, req.Target)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   fmt.Sprintf("Debug container %s added to pod %s", req.Name, podName),
+		"pod":       podName,
+		"namespace": namespace,
+		"container": req.Name,
+		"image":     req.Image,
+		"target":    req.Target,
+		"attach":    h.apiLink(c, "/pod/debug/attach/%s/%s", namespace, podName) + "&container=" + req.Name,
+	})
+}
+
+// AttachDebugContainerHandler - WebSocket-терминал к ephemeral-контейнеру (ждет его запуска)
+func (h *Handler) AttachDebugContainerHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	podName := c.Param("pod")
+	container := c.Query("container")
+
+	if container == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "container is required"})
+		return
+	}
+
+	idleTimeout, ok := parseIdleTimeout(c)
+	if !ok {
+		return
+	}
+
+	cluster := h.cluster(c)
+
+	pod, err := cluster.Clientset.CoreV1().Pods(namespace).Get(c.Request.Context(), podName, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if !hasContainer(pod, container) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Container not found in pod: " + container})
+		return
+	}
+
+	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade error: %v", err)
+		return
+	}
+	defer ws.Close()
+
+	conn := &execConn{ws: ws}
+
+	session, ctx := k8s.NewExecSession(context.Background(), "attach", cluster.Name, namespace, podName, container, nil, true)
+	defer session.Stop()
+
+	if err := waitForEphemeralContainer(ctx, conn, cluster, namespace, podName, container); err != nil {
+		conn.send(ExecMessage{Type: "error", Data: err.Error()})
+		return
+	}
+
+	serveTerminal(ctx, conn, session, idleTimeout, func(streams remotecommand.StreamOptions) error {
+		return k8s.StartAttach(ctx, session, cluster, streams)
+	})
+}
+
+// waitForEphemeralContainer - ждет Running ephemeral-контейнера, сообщая клиенту о состоянии
+func waitForEphemeralContainer(ctx context.Context, conn *execConn, cluster *k8s.Cluster, namespace, podName, container string) error {
+	ctx, cancel := context.WithTimeout(ctx, debugStartTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(debugStatusInterval)
+	defer ticker.Stop()
+
+	lastState := ""
+	for {
+		pod, err := cluster.Clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get pod: %w", err)
+		}
+
+		state, found := ephemeralContainerState(pod, container)
+		switch {
+		case !found:
+			state = "Pending"
+		case state == "Running":
+			return nil
+		case stuckWaitingReasons[state]:
+			return fmt.Errorf("debug container %s cannot start: %s", container, state)
+		case state == "Terminated":
+			return fmt.Errorf("debug container %s has already terminated", container)
+		}
+
+		if state != lastState {
+			lastState = state
+			conn.send(ExecMessage{
+				Type: "info",
+				Data: fmt.Sprintf("Waiting for debug container %s to start (%s)...", container, state),
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("debug container %s did not start within %s", container, debugStartTimeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// ephemeralContainerState - Running/Terminated или причина ожидания ephemeral-контейнера
+func ephemeralContainerState(pod *corev1.Pod, container string) (string, bool) {
+	for _, status := range pod.Status.EphemeralContainerStatuses {
+		if status.Name != container {
+			continue
+		}
+		switch {
+		case status.State.Running != nil:
+			return "Running", true
+		case status.State.Terminated != nil:
+			return "Terminated", true
+		case status.State.Waiting != nil:
+			return status.State.Waiting.Reason, true
+		}
+		return "Unknown", true
+	}
+	return "", false
+}
+
+// ephemeralContainersInfo - ephemeral-контейнеры пода с их состоянием
+func ephemeralContainersInfo(pod *corev1.Pod) []gin.H {
+	statuses := make(map[string]corev1.ContainerStatus)
+	for _, status := range pod.Status.EphemeralContainerStatuses {
+		statuses[status.Name] = status
+	}
+
+	result := []gin.H{}
+	for _, container := range pod.Spec.EphemeralContainers {
+		info := gin.H{
+			"name":    container.Name,
+			"image":   container.Image,
+			"target":  container.TargetContainerName,
+			"command": container.Command,
+			"state":   "Pending",
+		}
+
+		if status, ok := statuses[container.Name]; ok {
+			state, _ := ephemeralContainerState(pod, container.Name)
+			info["state"] = state
+			info["ready"] = status.Ready
+			info["restartCount"] = status.RestartCount
+			info["details"] = status.State
+		}
+
+		result = append(result, info)
+	}
+	return result
+}
@@ -7,20 +7,22 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"gopkg.in/yaml.v3"
-	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	sigsyaml "sigs.k8s.io/yaml"
 )
 
 func (h *Handler) GetDeploymentsHandler(c *gin.Context) {
 	namespace := c.DefaultQuery("namespace", "market")
 
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
 		return
 	}
 
-	deployments, err := h.clientset.AppsV1().Deployments(namespace).List(c.Request.Context(), metav1.ListOptions{})
+	deployments, err := h.resources(c).ListDeployments(c.Request.Context(), namespace, liveRead(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -50,6 +52,7 @@ func (h *Handler) GetDeploymentsHandler(c *gin.Context) {
 		"namespace":   namespace,
 		"count":       len(deployments.Items),
 		"deployments": result,
+		"source":      readSource(c),
 	})
 }
 
@@ -57,12 +60,13 @@ func (h *Handler) GetDeploymentYAMLHandler(c *gin.Context) {
 	namespace := c.Param("namespace")
 	name := c.Param("name")
 
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
 		return
 	}
 
-	deployment, err := h.clientset.AppsV1().Deployments(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -75,7 +79,7 @@ func (h *Handler) GetDeploymentYAMLHandler(c *gin.Context) {
 		Kind:       "Deployment",
 	}
 
-	yamlData, err := yaml.Marshal(deployment)
+	yamlData, err := sigsyaml.Marshal(deployment)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -88,57 +92,134 @@ func (h *Handler) GetDeploymentYAMLHandler(c *gin.Context) {
 	})
 }
 
-func (h *Handler) UpdateDeploymentYAMLHandler(c *gin.Context) {
+// GetDeploymentDetailsHandler - детали деплоймента: статус, условия, ReplicaSet'ы, поды
+// и общая хронология событий деплоймента, его ReplicaSet'ов и подов
+func (h *Handler) GetDeploymentDetailsHandler(c *gin.Context) {
 	namespace := c.Param("namespace")
 	name := c.Param("name")
 
-	var request struct {
-		YAML string `json:"yaml"`
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
 	}
 
-	if err := c.BindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	ctx := c.Request.Context()
+
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	if h.clientset == nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+	revisions, err := deploymentRevisions(ctx, clientset, deployment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Декодируем YAML
-	var deployment appsv1.Deployment
-	if err := yaml.Unmarshal([]byte(request.YAML), &deployment); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid YAML: " + err.Error()})
-		return
+	uids := map[types.UID]bool{deployment.UID: true}
+
+	replicaSets := []gin.H{}
+	for i := len(revisions) - 1; i >= 0; i-- {
+		rs := revisions[i].ReplicaSet
+		uids[rs.UID] = true
+
+		desired := int32(0)
+		if rs.Spec.Replicas != nil {
+			desired = *rs.Spec.Replicas
+		}
+		replicaSets = append(replicaSets, gin.H{
+			"name":     rs.Name,
+			"revision": revisions[i].Revision,
+			"ready":    fmt.Sprintf("%d/%d", rs.Status.ReadyReplicas, desired),
+			"replicas": desired,
+			"age":      time.Since(rs.CreationTimestamp.Time).Round(time.Second).String(),
+		})
 	}
 
-	// Проверяем имя
-	if deployment.Name != name || deployment.Namespace != namespace {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Name or namespace mismatch"})
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Обновляем деплоймент
-	_, err := h.clientset.AppsV1().Deployments(namespace).Update(c.Request.Context(), &deployment, metav1.UpdateOptions{})
+	podList, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	pods := []gin.H{}
+	for _, pod := range podList.Items {
+		ref := controllerRef(pod.OwnerReferences)
+		if ref == nil || !uids[ref.UID] {
+			continue
+		}
+		uids[pod.UID] = true
+
+		ready := 0
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Ready {
+				ready++
+			}
+		}
+		pods = append(pods, gin.H{
+			"name":       pod.Name,
+			"status":     pod.Status.Phase,
+			"ready":      fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers)),
+			"restarts":   getRestartCount(pod),
+			"node":       pod.Spec.NodeName,
+			"replicaSet": ref.Name,
+		})
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message":   "Deployment updated successfully",
-		"name":      name,
-		"namespace": namespace,
+		"name":        name,
+		"namespace":   namespace,
+		"replicas":    replicas,
+		"ready":       fmt.Sprintf("%d/%d", deployment.Status.ReadyReplicas, replicas),
+		"updated":     deployment.Status.UpdatedReplicas,
+		"available":   deployment.Status.AvailableReplicas,
+		"paused":      deployment.Spec.Paused,
+		"strategy":    deployment.Spec.Strategy,
+		"selector":    selector.String(),
+		"labels":      deployment.Labels,
+		"conditions":  deployment.Status.Conditions,
+		"age":         time.Since(deployment.CreationTimestamp.Time).Round(time.Second).String(),
+		"replicaSets": replicaSets,
+		"pods":        pods,
+		"events":      eventTimeline(ctx, clientset, namespace, uids),
 	})
 }
 
+func (h *Handler) UpdateDeploymentYAMLHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	// Server-side apply: неизвестные поля сохраняются, resourceVersion не проверяется.
+	// ?dryRun=All - только валидация, ?force=true - забрать поля у других менеджеров
+	response := h.applyEditedYAML(c, schema.GroupKind{Group: "apps", Kind: "Deployment"}, namespace, name)
+	if response == nil {
+		return
+	}
+
+	response["name"] = name
+	c.JSON(http.StatusOK, response)
+}
+
 func (h *Handler) ScaleDeploymentHandler(c *gin.Context) {
 	namespace := c.Param("namespace")
 	deploymentName := c.Param("deployment")
 	replicasStr := c.DefaultQuery("replicas", "1")
 
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
 		return
 	}
@@ -149,7 +230,7 @@ func (h *Handler) ScaleDeploymentHandler(c *gin.Context) {
 		return
 	}
 
-	deployment, err := h.clientset.AppsV1().Deployments(namespace).Get(
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(
 		c.Request.Context(), deploymentName, metav1.GetOptions{})
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Deployment not found: " + err.Error()})
@@ -157,7 +238,7 @@ func (h *Handler) ScaleDeploymentHandler(c *gin.Context) {
 	}
 
 	deployment.Spec.Replicas = int32Ptr(int32(replicas))
-	_, err = h.clientset.AppsV1().Deployments(namespace).Update(
+	_, err = clientset.AppsV1().Deployments(namespace).Update(
 		c.Request.Context(), deployment, metav1.UpdateOptions{})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -176,12 +257,13 @@ func (h *Handler) RestartDeploymentHandler(c *gin.Context) {
 	namespace := c.Param("namespace")
 	deploymentName := c.Param("deployment")
 
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
 		return
 	}
 
-	deployment, err := h.clientset.AppsV1().Deployments(namespace).Get(
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(
 		c.Request.Context(), deploymentName, metav1.GetOptions{})
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Deployment not found"})
@@ -195,7 +277,7 @@ func (h *Handler) RestartDeploymentHandler(c *gin.Context) {
 	deployment.Spec.Template.ObjectMeta.Annotations["kubectl.kubernetes.io/restartedAt"] =
 		time.Now().Format(time.RFC3339)
 
-	_, err = h.clientset.AppsV1().Deployments(namespace).Update(
+	_, err = clientset.AppsV1().Deployments(namespace).Update(
 		c.Request.Context(), deployment, metav1.UpdateOptions{})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -213,12 +295,13 @@ func (h *Handler) DeleteDeploymentHandler(c *gin.Context) {
 	namespace := c.Param("namespace")
 	deploymentName := c.Param("deployment")
 
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
 		return
 	}
 
-	err := h.clientset.AppsV1().Deployments(namespace).Delete(
+	err := clientset.AppsV1().Deployments(namespace).Delete(
 		c.Request.Context(), deploymentName, metav1.DeleteOptions{})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
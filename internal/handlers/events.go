@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"k8s-manager/internal/k8s"
+)
+
+const defaultEventsLimit = 500
+
+// eventFieldSelector - фильтры событий (kind, name, type, reason) как fieldSelector API
+func eventFieldSelector(kind, name, eventType, reason string) (fields.Selector, error) {
+	set := fields.Set{}
+	if kind != "" {
+		set["involvedObject.kind"] = kind
+	}
+	if name != "" {
+		set["involvedObject.name"] = name
+	}
+	if eventType != "" {
+		switch strings.ToLower(eventType) {
+		case "warning":
+			set["type"] = corev1.EventTypeWarning
+		case "normal":
+			set["type"] = corev1.EventTypeNormal
+		default:
+			return nil, fmt.Errorf("invalid type %q, expected Warning or Normal", eventType)
+		}
+	}
+	if reason != "" {
+		set["reason"] = reason
+	}
+	return fields.SelectorFromSet(set), nil
+}
+
+// GetEventsHandler - события кластера, новые первыми.
+// ?namespace=all&kind=Pod&name=&type=Warning|Normal&reason=&limit=500
+func (h *Handler) GetEventsHandler(c *gin.Context) {
+	namespace := c.DefaultQuery("namespace", "all")
+	listNamespace := namespace
+	if namespace == "all" {
+		listNamespace = ""
+	}
+
+	selector, err := eventFieldSelector(c.Query("kind"), c.Query("name"), c.Query("type"), c.Query("reason"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := defaultEventsLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	events, err := clientset.CoreV1().Events(listNamespace).List(c.Request.Context(), metav1.ListOptions{
+		FieldSelector: selector.String(),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	items := events.Items
+	sortEvents(items, true)
+
+	total := len(items)
+	if len(items) > limit {
+		items = items[:limit]
+	}
+
+	result := []gin.H{}
+	warnings := 0
+	for i := range items {
+		if items[i].Type == corev1.EventTypeWarning {
+			warnings++
+		}
+		result = append(result, eventInfo(&items[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"namespace": namespace,
+		"selector":  selector.String(),
+		"total":     total,
+		"count":     len(result),
+		"warnings":  warnings,
+		"events":    result,
+	})
+}
+
+// eventInfo - событие в виде для UI
+func eventInfo(event *corev1.Event) gin.H {
+	lastSeen := k8s.EventLastSeen(event)
+	return gin.H{
+		"type":    event.Type,
+		"reason":  event.Reason,
+		"message": event.Message,
+		"object": gin.H{
+			"kind":      event.InvolvedObject.Kind,
+			"name":      event.InvolvedObject.Name,
+			"namespace": event.InvolvedObject.Namespace,
+			"fieldPath": event.InvolvedObject.FieldPath,
+		},
+		"count":     k8s.EventCount(event),
+		"firstSeen": k8s.EventFirstSeen(event).Format(time.RFC3339),
+		"lastSeen":  lastSeen.Format(time.RFC3339),
+		"age":       time.Since(lastSeen).Round(time.Second).String(),
+		"source":    k8s.EventSource(event),
+	}
+}
+
+// sortEvents - по времени последнего повторения
+func sortEvents(events []corev1.Event, newestFirst bool) {
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := k8s.EventLastSeen(&events[i]), k8s.EventLastSeen(&events[j])
+		if newestFirst {
+			return a.After(b)
+		}
+		return a.Before(b)
+	})
+}
+
+// eventTimeline - события указанных объектов namespace в хронологическом порядке (как в kubectl describe).
+// Ошибка чтения событий не мешает ответу с деталями объекта - возвращается пустой список.
+func eventTimeline(ctx context.Context, clientset *kubernetes.Clientset, namespace string, uids map[types.UID]bool) []gin.H {
+	result := []gin.H{}
+
+	events, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("⚠️  Failed to list events in %s: %v", namespace, err)
+		return result
+	}
+
+	var matched []corev1.Event
+	for _, event := range events.Items {
+		if uids[event.InvolvedObject.UID] {
+			matched = append(matched, event)
+		}
+	}
+	sortEvents(matched, false)
+
+	for i := range matched {
+		result = append(result, eventInfo(&matched[i]))
+	}
+	return result
+}
+
+// objectEvents - события одного объекта (по kind/name и uid, чтобы не смешивать с пересозданным)
+func objectEvents(ctx context.Context, clientset *kubernetes.Clientset, namespace, kind, name string, uid types.UID) []gin.H {
+	result := []gin.H{}
+
+	selector := fields.SelectorFromSet(fields.Set{
+		"involvedObject.kind": kind,
+		"involvedObject.name": name,
+		"involvedObject.uid":  string(uid),
+	})
+	events, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		log.Printf("⚠️  Failed to list events for %s %s/%s: %v", kind, namespace, name, err)
+		return result
+	}
+
+	sortEvents(events.Items, false)
+	for i := range events.Items {
+		result = append(result, eventInfo(&events.Items[i]))
+	}
+	return result
+}
+
+// StreamEventsHandler - новые события в реальном времени через WebSocket или SSE (по умолчанию только Warning).
+// ?namespace=all&type=Warning|Normal|all&kind=&name=&reason=&since=<секунд истории>
+func (h *Handler) StreamEventsHandler(c *gin.Context) {
+	namespace := c.DefaultQuery("namespace", "all")
+	if namespace == "all" {
+		namespace = ""
+	}
+
+	eventType := c.DefaultQuery("type", corev1.EventTypeWarning)
+	if strings.ToLower(eventType) == "all" {
+		eventType = ""
+	}
+
+	selector, err := eventFieldSelector(c.Query("kind"), c.Query("name"), eventType, c.Query("reason"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Начальный снимок хаба отбрасываем, кроме событий за последние since секунд
+	since := time.Now()
+	if sinceStr := c.Query("since"); sinceStr != "" {
+		seconds, err := strconv.Atoi(sinceStr)
+		if err != nil || seconds < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid since value"})
+			return
+		}
+		since = since.Add(-time.Duration(seconds) * time.Second)
+	}
+
+	cluster := h.cluster(c)
+	if cluster == nil || cluster.Watches == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	sub, err := cluster.Watches.Subscribe("events", namespace, nil, selector)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer sub.Close()
+
+	sink, err := openEventSink(c)
+	if err != nil {
+		log.Printf("WebSocket upgrade error: %v", err)
+		return
+	}
+	defer sink.Close()
+
+	sink.Send("", LogMessage{
+		Type:    "info",
+		Message: fmt.Sprintf("Streaming events [%s]", selector.String()),
+		Time:    time.Now().Format(time.RFC3339),
+	})
+
+	pingTicker := time.NewTicker(sink.PingInterval())
+	defer pingTicker.Stop()
+
+	// MODIFIED несет только изменившиеся поля - полную сводку собираем здесь
+	known := make(map[string]map[string]interface{})
+
+	send := func() bool {
+		for _, event := range sub.Drain() {
+			key := event.Namespace + "/" + event.Name
+
+			switch event.Type {
+			case k8s.WatchDeleted:
+				delete(known, key)
+				continue
+			case k8s.WatchAdded:
+				// Сводка общая с хабом - храним копию
+				summary := make(map[string]interface{}, len(event.Object))
+				for field, value := range event.Object {
+					summary[field] = value
+				}
+				known[key] = summary
+			case k8s.WatchModified:
+				summary := known[key]
+				if summary == nil {
+					continue
+				}
+				for field, value := range event.Object {
+					summary[field] = value
+				}
+			}
+
+			summary := known[key]
+			lastSeen, _ := time.Parse(time.RFC3339, fmt.Sprint(summary["lastSeen"]))
+			if lastSeen.Before(since.Truncate(time.Second)) {
+				continue
+			}
+
+			msgType := "info"
+			if summary["type"] == corev1.EventTypeWarning {
+				msgType = "warning"
+			}
+
+			data := gin.H{"namespace": event.Namespace, "name": event.Name}
+			for field, value := range summary {
+				data[field] = value
+			}
+
+			if err := sink.Send(event.ResourceVersion, LogMessage{
+				Type:    msgType,
+				Message: fmt.Sprintf("%s %s: %s", summary["object"], summary["reason"], summary["message"]),
+				Data:    data,
+				Time:    time.Now().Format(time.RFC3339),
+			}); err != nil {
+				return false
+			}
+		}
+		return true
+	}
+
+	for {
+		select {
+		case <-sink.Closed():
+			return
+
+		case <-pingTicker.C:
+			if err := sink.Ping(); err != nil {
+				return
+			}
+
+		case <-sub.Ready():
+			if !send() {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,388 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"k8s-manager/internal/k8s"
+)
+
+const (
+	defaultExecIdleTimeout = 15 * time.Minute
+	maxExecIdleTimeout     = 4 * time.Hour
+)
+
+// defaultExecCommand - bash, если он есть в образе, иначе sh
+var defaultExecCommand = []string{"/bin/sh", "-c", "command -v bash >/dev/null 2>&1 && exec bash || exec sh"}
+
+// ExecMessage - сообщение терминала в обе стороны WebSocket.
+// Клиент: stdin (data), resize (cols, rows). Сервер: stdout, stderr, info, error, exit (code).
+type ExecMessage struct {
+	Type string `json:"type"`
+	Data string `json:"data,omitempty"`
+	Cols uint16 `json:"cols,omitempty"`
+	Rows uint16 `json:"rows,omitempty"`
+	Code *int   `json:"code,omitempty"`
+	Time string `json:"time,omitempty"`
+}
+
+// execConn - WebSocket с сериализацией записи (stdout, stderr и служебные сообщения пишутся параллельно)
+type execConn struct {
+	ws *websocket.Conn
+	mu sync.Mutex
+}
+
+func (c *execConn) send(msg ExecMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	msg.Time = time.Now().Format(time.RFC3339)
+	return c.ws.WriteJSON(msg)
+}
+
+// execOutput - io.Writer потока вывода; не разрывает UTF-8 символы между сообщениями
+type execOutput struct {
+	conn    *execConn
+	session *k8s.ExecSession
+	stream  string
+	pending []byte
+}
+
+func (w *execOutput) Write(p []byte) (int, error) {
+	w.session.Touch()
+
+	data := append(w.pending, p...)
+
+	// Хвост с неполным символом откладываем до следующей записи
+	cut := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	w.pending = append([]byte(nil), data[cut:]...)
+
+	if cut > 0 {
+		if err := w.conn.send(ExecMessage{Type: w.stream, Data: string(data[:cut])}); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// terminalSizeQueue - события resize от клиента для remotecommand
+type terminalSizeQueue struct {
+	sizes chan remotecommand.TerminalSize
+	ctx   context.Context
+}
+
+func (q *terminalSizeQueue) Next() *remotecommand.TerminalSize {
+	select {
+	case size := <-q.sizes:
+		return &size
+	case <-q.ctx.Done():
+		return nil
+	}
+}
+
+// ExecHandler - интерактивный терминал в контейнере через WebSocket
+func (h *Handler) ExecHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	podName := c.Param("pod")
+
+	if !isValidNamespace(namespace) || !isValidPodName(podName) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid namespace or pod name"})
+		return
+	}
+
+	// command можно передать строкой (?command=ls -la) или несколькими параметрами
+	command := c.QueryArray("command")
+	if len(command) == 1 {
+		command = strings.Fields(command[0])
+	}
+	if len(command) == 0 {
+		command = defaultExecCommand
+	}
+
+	tty := c.DefaultQuery("tty", "true") != "false"
+
+	idleTimeout, ok := parseIdleTimeout(c)
+	if !ok {
+		return
+	}
+
+	cluster := h.cluster(c)
+
+	pod, err := cluster.Clientset.CoreV1().Pods(namespace).Get(c.Request.Context(), podName, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if pod.Status.Phase != corev1.PodRunning {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Pod is %s, exec requires a running pod", pod.Status.Phase)})
+		return
+	}
+
+	container := c.Query("container")
+	if container == "" {
+		container = defaultContainer(pod)
+	} else if !hasContainer(pod, container) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Container not found in pod: " + container})
+		return
+	}
+
+	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade error: %v", err)
+		return
+	}
+	defer ws.Close()
+
+	session, ctx := k8s.NewExecSession(context.Background(), "exec", cluster.Name, namespace, podName, container, command, tty)
+	conn := &execConn{ws: ws}
+
+	serveTerminal(ctx, conn, session, idleTimeout, func(streams remotecommand.StreamOptions) error {
+		return k8s.StartExec(ctx, session, cluster, streams)
+	})
+}
+
+// serveTerminal - связывает WebSocket с exec/attach-сессией: ввод, resize, вывод, idle-таймаут и код завершения
+func serveTerminal(ctx context.Context, conn *execConn, session *k8s.ExecSession, idleTimeout time.Duration, run func(streams remotecommand.StreamOptions) error) {
+	defer session.Stop()
+
+	manager := k8s.GetExecManager()
+	manager.AddSession(session)
+	defer manager.RemoveSession(session.ID)
+
+	conn.send(ExecMessage{
+		Type: "info",
+		Data: fmt.Sprintf("Connected to %s/%s [%s], session %s", session.Namespace, session.Pod, session.Container, session.ID),
+	})
+
+	stdinReader, stdinWriter := io.Pipe()
+	defer stdinReader.Close()
+
+	sizes := &terminalSizeQueue{sizes: make(chan remotecommand.TerminalSize, 1), ctx: ctx}
+
+	// Чтение ввода и resize от клиента
+	go func() {
+		defer session.Stop()
+		defer stdinWriter.Close()
+
+		for {
+			_, data, err := conn.ws.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			var msg ExecMessage
+			if err := json.Unmarshal(data, &msg); err != nil {
+				continue
+			}
+
+			switch msg.Type {
+			case "stdin":
+				session.Touch()
+				if _, err := stdinWriter.Write([]byte(msg.Data)); err != nil {
+					return
+				}
+			case "resize":
+				if msg.Cols == 0 || msg.Rows == 0 {
+					continue
+				}
+				// Важен только последний размер
+				select {
+				case <-sizes.sizes:
+				default:
+				}
+				sizes.sizes <- remotecommand.TerminalSize{Width: msg.Cols, Height: msg.Rows}
+			}
+		}
+	}()
+
+	// Idle-таймаут
+	go func() {
+		ticker := time.NewTicker(idleCheckInterval(idleTimeout))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if session.IdleFor() >= idleTimeout {
+					conn.send(ExecMessage{
+						Type: "info",
+						Data: fmt.Sprintf("Session closed after %s of inactivity", idleTimeout),
+					})
+					session.Stop()
+					return
+				}
+			}
+		}
+	}()
+
+	streams := remotecommand.StreamOptions{
+		Stdin:  stdinReader,
+		Stdout: &execOutput{conn: conn, session: session, stream: "stdout"},
+		Tty:    session.TTY,
+	}
+	if session.TTY {
+		streams.TerminalSizeQueue = sizes
+	} else {
+		streams.Stderr = &execOutput{conn: conn, session: session, stream: "stderr"}
+	}
+
+	err := run(streams)
+
+	// Код завершения команды
+	exitCode := 0
+	if err != nil {
+		if exitErr, ok := err.(interface{ ExitStatus() int }); ok {
+			exitCode = exitErr.ExitStatus()
+		} else if ctx.Err() == nil {
+			conn.send(ExecMessage{Type: "error", Data: fmt.Sprintf("%s error: %v", session.Mode, err)})
+			return
+		}
+	}
+
+	if ctx.Err() == nil {
+		conn.send(ExecMessage{Type: "exit", Code: &exitCode})
+	}
+}
+
+// GetExecSessionsHandler - активные exec-сессии
+func (h *Handler) GetExecSessionsHandler(c *gin.Context) {
+	sessions := k8s.GetExecManager().GetSessions()
+
+	result := []gin.H{}
+	for _, session := range sessions {
+		result = append(result, gin.H{
+			"id":           session.ID,
+			"mode":         session.Mode,
+			"cluster":      session.Cluster,
+			"namespace":    session.Namespace,
+			"pod":          session.Pod,
+			"container":    session.Container,
+			"command":      session.Command,
+			"tty":          session.TTY,
+			"status":       session.CurrentStatus(),
+			"createdAt":    session.CreatedAt.Format(time.RFC3339),
+			"lastActivity": session.LastActivity().Format(time.RFC3339),
+			"idleDuration": session.IdleFor().Round(time.Second).String(),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":    len(result),
+		"sessions": result,
+	})
+}
+
+// StopExecSessionHandler - принудительное закрытие exec-сессии
+func (h *Handler) StopExecSessionHandler(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	if !k8s.GetExecManager().StopSession(sessionID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Exec session not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Exec session stopped",
+		"session": sessionID,
+	})
+}
+
+// resolveContainer - проверяет контейнер пода или выбирает контейнер по умолчанию; при ошибке пишет ответ
+func (h *Handler) resolveContainer(c *gin.Context, namespace, podName, container string) (string, bool) {
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return "", false
+	}
+
+	pod, err := clientset.CoreV1().Pods(namespace).Get(c.Request.Context(), podName, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return "", false
+	}
+
+	if container == "" {
+		return defaultContainer(pod), true
+	}
+	if !hasContainer(pod, container) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Container not found in pod: " + container})
+		return "", false
+	}
+	return container, true
+}
+
+// defaultContainer - контейнер по умолчанию (аннотация kubectl, иначе первый)
+func defaultContainer(pod *corev1.Pod) string {
+	if name := pod.Annotations["kubectl.kubernetes.io/default-container"]; name != "" && hasContainer(pod, name) {
+		return name
+	}
+	if len(pod.Spec.Containers) > 0 {
+		return pod.Spec.Containers[0].Name
+	}
+	return ""
+}
+
+func hasContainer(pod *corev1.Pod, name string) bool {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == name {
+			return true
+		}
+	}
+	for _, container := range pod.Spec.EphemeralContainers {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIdleTimeout - ?idle=секунды; при ошибке пишет ответ
+func parseIdleTimeout(c *gin.Context) (time.Duration, bool) {
+	idleStr := c.Query("idle")
+	if idleStr == "" {
+		return defaultExecIdleTimeout, true
+	}
+
+	seconds, err := strconv.Atoi(idleStr)
+	if err != nil || seconds <= 0 || time.Duration(seconds)*time.Second > maxExecIdleTimeout {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid idle timeout value"})
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+func idleCheckInterval(idleTimeout time.Duration) time.Duration {
+	interval := idleTimeout / 10
+	if interval < time.Second {
+		return time.Second
+	}
+	if interval > 30*time.Second {
+		return 30 * time.Second
+	}
+	return interval
+}
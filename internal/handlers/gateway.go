@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Ресурсы Gateway API (CRD, читаются через dynamic client)
+var (
+	gatewayResource   = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways"}
+	httpRouteResource = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}
+)
+
+// listGatewayAPI - объекты Gateway API; installed=false, если CRD не установлены
+func (h *Handler) listGatewayAPI(c *gin.Context, resource schema.GroupVersionResource, namespace string) (*unstructured.UnstructuredList, bool, error) {
+	list, err := h.cluster(c).Dynamic.Resource(resource).Namespace(namespace).List(c.Request.Context(), metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		return &unstructured.UnstructuredList{}, false, nil
+	}
+	if err != nil {
+		return nil, true, err
+	}
+	return list, true, nil
+}
+
+// nestedMaps - список объектов по пути (spec.listeners, spec.rules, ...)
+func nestedMaps(obj map[string]interface{}, fields ...string) []map[string]interface{} {
+	items, _, _ := unstructured.NestedSlice(obj, fields...)
+	result := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// conditionStatus - статус условия type из списка conditions
+func conditionStatus(conditions []map[string]interface{}, conditionType string) string {
+	for _, condition := range conditions {
+		if condition["type"] == conditionType {
+			status, _ := condition["status"].(string)
+			return status
+		}
+	}
+	return "Unknown"
+}
+
+// GetGatewaysHandler - Gateway с listeners, адресами и статусом Accepted/Programmed.
+// ?namespace=default|all
+func (h *Handler) GetGatewaysHandler(c *gin.Context) {
+	namespace := c.DefaultQuery("namespace", "default")
+	listNamespace := namespace
+	if namespace == "all" {
+		listNamespace = ""
+	}
+
+	if h.cluster(c) == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	gateways, installed, err := h.listGatewayAPI(c, gatewayResource, listNamespace)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := []gin.H{}
+	for _, gw := range gateways.Items {
+		attached := map[string]int64{}
+		for _, listener := range nestedMaps(gw.Object, "status", "listeners") {
+			name, _ := listener["name"].(string)
+			attached[name], _, _ = unstructured.NestedInt64(listener, "attachedRoutes")
+		}
+
+		listeners := []gin.H{}
+		for _, listener := range nestedMaps(gw.Object, "spec", "listeners") {
+			name, _ := listener["name"].(string)
+			port, _, _ := unstructured.NestedInt64(listener, "port")
+			listeners = append(listeners, gin.H{
+				"name":           name,
+				"hostname":       listener["hostname"],
+				"port":           port,
+				"protocol":       listener["protocol"],
+				"attachedRoutes": attached[name],
+			})
+		}
+
+		addresses := []string{}
+		for _, address := range nestedMaps(gw.Object, "status", "addresses") {
+			if value, ok := address["value"].(string); ok {
+				addresses = append(addresses, value)
+			}
+		}
+
+		class, _, _ := unstructured.NestedString(gw.Object, "spec", "gatewayClassName")
+		conditions := nestedMaps(gw.Object, "status", "conditions")
+		result = append(result, gin.H{
+			"name":       gw.GetName(),
+			"namespace":  gw.GetNamespace(),
+			"class":      class,
+			"listeners":  listeners,
+			"addresses":  addresses,
+			"accepted":   conditionStatus(conditions, "Accepted"),
+			"programmed": conditionStatus(conditions, "Programmed"),
+			"age":        time.Since(gw.GetCreationTimestamp().Time).Round(time.Second).String(),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"namespace": namespace,
+		"installed": installed,
+		"count":     len(result),
+		"gateways":  result,
+	})
+}
+
+// httpRouteMatches - пути правила HTTPRoute в виде "PathPrefix /api"
+func httpRouteMatches(rule map[string]interface{}) []string {
+	matches := []string{}
+	for _, match := range nestedMaps(rule, "matches") {
+		pathType, _, _ := unstructured.NestedString(match, "path", "type")
+		value, _, _ := unstructured.NestedString(match, "path", "value")
+		if pathType == "" && value == "" {
+			continue
+		}
+		if pathType == "" {
+			pathType = "PathPrefix"
+		}
+		matches = append(matches, pathType+" "+value)
+	}
+	if len(matches) == 0 {
+		// Правило без matches совпадает с любым путем
+		matches = append(matches, "PathPrefix /")
+	}
+	return matches
+}
+
+// httpRouteBackend - backendRef HTTPRoute -> сервис и его готовые поды
+func httpRouteBackend(idx *backendIndex, routeNamespace string, ref map[string]interface{}) gin.H {
+	kind, _ := ref["kind"].(string)
+	group, _ := ref["group"].(string)
+	name, _ := ref["name"].(string)
+	namespace, _ := ref["namespace"].(string)
+	if namespace == "" {
+		namespace = routeNamespace
+	}
+
+	weight, found, _ := unstructured.NestedInt64(ref, "weight")
+	if !found {
+		weight = 1
+	}
+
+	if (kind != "" && kind != "Service") || group != "" {
+		return gin.H{
+			"resource": kind + "/" + name,
+			"weight":   weight,
+			"warnings": []string{"non-Service backends are not resolved"},
+		}
+	}
+
+	port := ""
+	if number, found, _ := unstructured.NestedInt64(ref, "port"); found {
+		port = fmt.Sprint(number)
+	}
+
+	backend := idx.backend(namespace, name, port)
+	backend["weight"] = weight
+	return backend
+}
+
+// GetHTTPRoutesHandler - HTTPRoute: hostnames, пути правил и бэкенды с готовыми подами (по EndpointSlice).
+// ?namespace=default|all&live=true
+func (h *Handler) GetHTTPRoutesHandler(c *gin.Context) {
+	namespace := c.DefaultQuery("namespace", "default")
+	listNamespace := namespace
+	if namespace == "all" {
+		listNamespace = ""
+	}
+
+	if h.cluster(c) == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	routes, installed, err := h.listGatewayAPI(c, httpRouteResource, listNamespace)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	idx := h.newBackendIndex(c)
+	if len(routes.Items) > 0 {
+		idx.load(listNamespace)
+	}
+
+	result := []gin.H{}
+	for _, route := range routes.Items {
+		parents := []gin.H{}
+		for _, parent := range nestedMaps(route.Object, "spec", "parentRefs") {
+			parents = append(parents, gin.H{
+				"name":        parent["name"],
+				"namespace":   parent["namespace"],
+				"sectionName": parent["sectionName"],
+			})
+		}
+
+		accepted := []string{}
+		for _, parent := range nestedMaps(route.Object, "status", "parents") {
+			name, _, _ := unstructured.NestedString(parent, "parentRef", "name")
+			if conditionStatus(nestedMaps(parent, "conditions"), "Accepted") == "True" {
+				accepted = append(accepted, name)
+			}
+		}
+
+		rules := []gin.H{}
+		for _, rule := range nestedMaps(route.Object, "spec", "rules") {
+			backends := []gin.H{}
+			for _, ref := range nestedMaps(rule, "backendRefs") {
+				backends = append(backends, httpRouteBackend(idx, route.GetNamespace(), ref))
+			}
+			rules = append(rules, gin.H{
+				"matches":  httpRouteMatches(rule),
+				"backends": backends,
+			})
+		}
+
+		hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+		if hostnames == nil {
+			hostnames = []string{}
+		}
+
+		result = append(result, gin.H{
+			"name":       route.GetName(),
+			"namespace":  route.GetNamespace(),
+			"hostnames":  hostnames,
+			"parentRefs": parents,
+			"acceptedBy": accepted,
+			"rules":      rules,
+			"age":        time.Since(route.GetCreationTimestamp().Time).Round(time.Second).String(),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"namespace":  namespace,
+		"installed":  installed,
+		"count":      len(result),
+		"httpRoutes": result,
+		"source":     readSource(c),
+	})
+}
+
+// ProbeHTTPRouteHandler - HTTP-проба HTTPRoute изнутри кластера: первое правило, чей путь подходит к ?path=,
+// и его первый сервис-бэкенд
+func (h *Handler) ProbeHTTPRouteHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	path := c.DefaultQuery("path", "/")
+
+	cluster := h.cluster(c)
+	if cluster == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	route, err := cluster.Dynamic.Resource(httpRouteResource).Namespace(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, rule := range nestedMaps(route.Object, "spec", "rules") {
+		matched := ""
+		for _, match := range httpRouteMatches(rule) {
+			matchType, value, _ := strings.Cut(match, " ")
+			prefix := strings.TrimSuffix(value, "/")
+			if value == path || (matchType == "PathPrefix" && (path == prefix || prefix == "" || strings.HasPrefix(path, prefix+"/"))) {
+				matched = match
+				break
+			}
+		}
+		if matched == "" {
+			continue
+		}
+
+		for _, ref := range nestedMaps(rule, "backendRefs") {
+			if kind, _ := ref["kind"].(string); kind != "" && kind != "Service" {
+				continue
+			}
+			backendName, _ := ref["name"].(string)
+			backendNamespace, _ := ref["namespace"].(string)
+			if backendNamespace == "" {
+				backendNamespace = namespace
+			}
+			port := ""
+			if number, found, _ := unstructured.NestedInt64(ref, "port"); found {
+				port = fmt.Sprint(number)
+			}
+
+			idx := h.newBackendIndex(c)
+			c.JSON(http.StatusOK, gin.H{
+				"httpRoute": name,
+				"rule":      matched,
+				"backend":   idx.backend(backendNamespace, backendName, port),
+				"probe":     probeService(c.Request.Context(), cluster.Clientset, backendNamespace, backendName, port, path),
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{"error": "No service backend matches path " + path})
+}
@@ -2,32 +2,112 @@ package handlers
 
 import (
 	"net/http"
+	"net/url"
 	"time"
 
 	"fmt"
 
+	"k8s-manager/internal/k8s"
+
 	"github.com/gin-gonic/gin"
-	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
+// clusterKey - ключ gin.Context, под которым хранится выбранный кластер
+const clusterKey = "k8s-cluster"
+
 type Handler struct {
-	clientset     *kubernetes.Clientset
-	metricsClient *metricsv.Clientset
+	clusters *k8s.ClusterRegistry
 }
 
-func NewHandler(clientset *kubernetes.Clientset, metricsClient *metricsv.Clientset) *Handler {
+func NewHandler(clusters *k8s.ClusterRegistry) *Handler {
 	return &Handler{
-		clientset:     clientset,
-		metricsClient: metricsClient,
+		clusters: clusters,
+	}
+}
+
+// ClusterMiddleware - выбирает кластер по параметру пути или query ?cluster=
+func (h *Handler) ClusterMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("cluster")
+		if name == "" {
+			name = c.Query("cluster")
+		}
+
+		cluster, exists := h.clusters.Get(name)
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"error":   "Cluster not found",
+				"cluster": name,
+			})
+			return
+		}
+
+		c.Set(clusterKey, cluster)
+		c.Request = c.Request.WithContext(k8s.WithReadSource(c.Request.Context()))
+		c.Next()
+	}
+}
+
+// cluster - кластер текущего запроса
+func (h *Handler) cluster(c *gin.Context) *k8s.Cluster {
+	if value, exists := c.Get(clusterKey); exists {
+		if cluster, ok := value.(*k8s.Cluster); ok {
+			return cluster
+		}
+	}
+	return h.clusters.Default()
+}
+
+// clientset - клиент Kubernetes для кластера текущего запроса
+func (h *Handler) clientset(c *gin.Context) *kubernetes.Clientset {
+	cluster := h.cluster(c)
+	if cluster == nil {
+		return nil
 	}
+	return cluster.Clientset
+}
+
+// apiLink - ссылка на API-эндпоинт в кластере текущего запроса
+func (h *Handler) apiLink(c *gin.Context, format string, args ...interface{}) string {
+	return fmt.Sprintf("/api"+format, args...) + "?cluster=" + url.QueryEscape(h.cluster(c).Name)
+}
+
+// resources - кэш ресурсов (информеры) для кластера текущего запроса
+func (h *Handler) resources(c *gin.Context) *k8s.ResourceCache {
+	return h.cluster(c).Resources
+}
+
+// liveRead - ?live=true отключает кэш и читает напрямую из API
+func liveRead(c *gin.Context) bool {
+	return c.Query("live") == "true"
+}
+
+// readSource - откуда взяты данные ответа: cache или live (в том числе, когда кэш еще не синхронизирован)
+func readSource(c *gin.Context) string {
+	if source := k8s.ReadSourceFrom(c.Request.Context()); source != nil {
+		return source.String()
+	}
+	if liveRead(c) {
+		return "live"
+	}
+	return "cache"
+}
+
+// metricsClient - клиент Metrics API для кластера текущего запроса
+func (h *Handler) metricsClient(c *gin.Context) *metricsv.Clientset {
+	cluster := h.cluster(c)
+	if cluster == nil {
+		return nil
+	}
+	return cluster.MetricsClient
 }
 
 func (h *Handler) HomeHandler(c *gin.Context) {
 	status := "disconnected"
-	if h.clientset != nil {
+	if h.clientset(c) != nil {
 		status = "connected"
 	}
 
@@ -35,48 +115,119 @@ func (h *Handler) HomeHandler(c *gin.Context) {
 		"name":    "Kubernetes Manager API",
 		"version": "1.0.0",
 		"status":  status,
+		"cluster": h.cluster(c).Name,
 		"endpoints": []string{
 			"GET  /api/health - Health check",
 			"GET  /api/test - Test K8s connection",
+			"GET  /api/clusters - List clusters from kubeconfig",
+			"ANY  /api/clusters/:cluster/... or /api/...?cluster=name - Target a specific cluster",
 			"GET  /api/applications - List applications",
-			"GET  /api/pods?namespace=default - List pods",
-			"GET  /api/logs/:namespace/:pod?tail=100 - Get pod logs",
-			"GET  /api/logs/download/:namespace/:pod - Download logs",
+			"GET  /api/pods?namespace=default&live=true - List pods (live=true bypasses the cache)",
+			"GET  /api/logs/:namespace/:pod?tail=100&container=&previous=&sinceSeconds=&sinceTime=&timestamps=&limitBytes=&all_containers= - Get pod logs",
+			"GET  /api/logs/download/:namespace/:pod - Download logs (same options)",
+			"     log filters (REST and WS): include=regex&exclude=regex&level=error,warn&structured=true",
+			"WS   /api/logs/stream/:namespace/:pod?follow=true&buffer=1000 - Stream pod logs (same options as GET)",
+			"WS   /api/logs/aggregate/:namespace?selector=|deployment=|statefulset=&container=regex - Tail logs of all matching pods",
+			"WS   /api/watch/:resource?namespace=default|all&labelSelector=&fieldSelector= - Watch pods, deployments, services, events or nodes (slim diffs)",
+			"GET  /api/watch - Watchable resources and active watch feeds",
+			"GET  /api/events?namespace=all&kind=&name=&type=Warning|Normal&reason=&limit=500 - List events, newest first",
+			"WS   /api/events/stream?namespace=all&type=Warning|Normal|all&kind=&name=&reason=&since=0 - Stream new events (WS or SSE)",
+			"     WS streams also speak SSE: Accept: text/event-stream or ?transport=sse; resume with Last-Event-ID or ?lastEventId=",
+			"POST /api/capture/start - Capture logs to disk in background {namespace, pod|selector|deployment|statefulset, container, tail}",
+			"GET  /api/capture/sessions - List capture sessions",
+			"POST /api/capture/stop/:id - Stop capture session",
+			"DELETE /api/capture/:id - Remove capture session from the list (stops it first; files stay on disk)",
+			"GET  /api/capture/files?session=&pod= - List captured files and rotated archives",
+			"GET  /api/capture/search?q=regex&session=&pod=&limit=200 - Search captured logs",
+			"GET  /api/capture/download?file= - Download captured file",
+			"GET  /api/pod/files/:namespace/:pod?path=&container=&format=tar|zip - Download file or directory from container",
+			"POST /api/pod/files/:namespace/:pod?path=&container= - Upload file into container (multipart field: file)",
 			"GET  /api/pod/yaml/:namespace/:pod - Get pod YAML",
-			"PUT  /api/pod/yaml/:namespace/:pod - Update pod YAML",
+			"PUT  /api/pod/yaml/:namespace/:pod - Update pod YAML (server-side apply, ?dryRun=All&force=true)",
 			"DELETE /api/pod/:namespace/:pod - Delete pod",
+			"GET  /api/pod/owners/:namespace/:pod - Resolve pod owner chain",
+			"POST /api/pod/debug/:namespace/:pod - Add ephemeral debug container {image, target, name, command}",
+			"WS   /api/pod/debug/attach/:namespace/:pod?container= - Attach terminal to debug container",
 			"GET  /api/deployments?namespace=default - List deployments",
 			"GET  /api/deployment/yaml/:namespace/:name - Get deployment YAML",
-			"PUT  /api/deployment/yaml/:namespace/:name - Update deployment YAML",
+			"GET  /api/deployment/details/:namespace/:name - Deployment details with ReplicaSets, pods and event timeline",
+			"PUT  /api/deployment/yaml/:namespace/:name - Update deployment YAML (server-side apply, ?dryRun=All&force=true)",
 			"POST /api/scale/:namespace/:deployment?replicas=N - Scale deployment",
 			"POST /api/restart/:namespace/:deployment - Restart deployment",
 			"DELETE /api/deployment/:namespace/:deployment - Delete deployment",
-			"GET  /api/services?namespace=default - List services",
+			"GET  /api/deployment/history/:namespace/:name?from=N&to=M - Rollout history with template diffs",
+			"POST /api/deployment/rollback/:namespace/:name?revision=N - Rollback to revision (default: previous)",
+			"POST /api/deployment/pause/:namespace/:name - Pause rollout",
+			"POST /api/deployment/resume/:namespace/:name - Resume rollout",
+			"WS   /api/deployment/rollout/:namespace/:name?timeout=600 - Stream rollout status (WS or SSE)",
+			"GET  /api/statefulsets?namespace=default - List statefulsets with per-ordinal pods and PVCs",
+			"GET  /api/statefulset/yaml/:namespace/:name - Get statefulset YAML",
+			"PUT  /api/statefulset/yaml/:namespace/:name - Update statefulset YAML (server-side apply, ?dryRun=All&force=true)",
+			"POST /api/statefulset/scale/:namespace/:name?replicas=N - Scale statefulset",
+			"POST /api/statefulset/restart/:namespace/:name - Restart statefulset",
+			"POST /api/statefulset/partition/:namespace/:name?partition=N - Set rolling update partition",
+			"DELETE /api/statefulset/:namespace/:name - Delete statefulset",
+			"GET  /api/daemonsets?namespace=default - List daemonsets",
+			"GET  /api/replicasets?namespace=default - List replicasets",
+			"GET  /api/jobs?namespace=default - List jobs",
+			"GET  /api/job/pods/:namespace/:name - List pods of a job run with log links",
+			"DELETE /api/job/:namespace/:name?propagation=Background - Delete job",
+			"DELETE /api/jobs/finished/:namespace?cronjob=&propagation= - Delete finished jobs",
+			"GET  /api/cronjobs?namespace=default - List cronjobs",
+			"GET  /api/cronjob/history/:namespace/:name - List cronjob runs",
+			"POST /api/cronjob/trigger/:namespace/:name - Run cronjob now",
+			"POST /api/cronjob/suspend/:namespace/:name - Suspend cronjob",
+			"POST /api/cronjob/resume/:namespace/:name - Resume cronjob",
+			"GET  /api/services?namespace=default - List services with EndpointSlice addresses, selected pods and selector/targetPort issues",
+			"GET  /api/service/yaml/:namespace/:name - Get service YAML and ingresses pointing at it",
+			"GET  /api/ingresses?namespace=default|all - List ingresses: host/path -> service -> ready pods (EndpointSlices)",
+			"GET  /api/ingressclasses - List ingress classes",
+			"POST /api/ingress/probe/:namespace/:name?host=&path= - HTTP probe of an ingress route from inside the cluster",
+			"GET  /api/gateways?namespace=default|all - List Gateway API gateways",
+			"GET  /api/httproutes?namespace=default|all - List HTTPRoutes with backend services and ready pods",
+			"POST /api/httproute/probe/:namespace/:name?path=/ - HTTP probe of an HTTPRoute from inside the cluster",
 			"GET  /api/configmaps/:namespace - List configmaps",
 			"GET  /api/secrets/:namespace - List secrets",
 			"GET  /api/namespaces - List namespaces",
 			"GET  /api/nodes - List nodes",
+			"POST /api/diff - Diff edited YAML {yaml, namespace} against live object (server dry run: no status, managedFields or defaults)",
+			"POST /api/apply?namespace=default&dryRun=All&force=true - Apply multi-document YAML (body, JSON {yaml} or multipart file), namespaces and CRDs first",
+			"GET  /api/resources - API resources from discovery (built-in and CRDs)",
+			"GET  /api/resources/:group/:version/:resource?namespace=all&labelSelector= - List any resource with CRD printer columns (group core for v1)",
+			"GET  /api/resources/:group/:version/:resource/:name?namespace= - Get object YAML",
+			"PUT  /api/resources/:group/:version/:resource/:name?namespace=&dryRun=All&force=true - Update object YAML (server-side apply)",
+			"DELETE /api/resources/:group/:version/:resource/:name?namespace=&propagation=Background - Delete object",
 			"GET  /api/metrics/pods/:namespace - Get pod metrics",
 			"GET  /api/metrics/nodes - Get node metrics",
 			"GET  /api/portforward/sessions - Get active port-forward sessions",
-			"POST /api/portforward/start - Start port-forward",
+			"POST /api/portforward/start - Start port-forward to a pod, service, deployment or statefulset (fails over to another ready pod)",
 			"POST /api/portforward/stop/:id - Stop port-forward",
+			"WS   /api/exec/:namespace/:pod?container=&command=&tty=true&idle=900 - Interactive exec terminal",
+			"GET  /api/exec/sessions - Get active exec sessions",
+			"POST /api/exec/stop/:id - Stop exec session",
 		},
 	})
 }
 
 func (h *Handler) HealthHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
-		"status":  "healthy",
-		"service": "k8s-manager",
-		"k8s":     h.clientset != nil,
-		"metrics": h.metricsClient != nil,
-		"time":    time.Now().Format(time.RFC3339),
+		"status":   "healthy",
+		"service":  "k8s-manager",
+		"cluster":  h.cluster(c).Name,
+		"clusters": len(h.clusters.List()),
+		"k8s":      h.clientset(c) != nil,
+		"metrics":  h.metricsClient(c) != nil,
+		"cache": gin.H{
+			"started": h.resources(c).Started(),
+			"synced":  h.resources(c).SyncStatus(),
+		},
+		"time": time.Now().Format(time.RFC3339),
 	})
 }
 
 func (h *Handler) TestConnectionHandler(c *gin.Context) {
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{
 			"connected": false,
 			"error":     "Kubernetes client not initialized",
@@ -84,7 +235,7 @@ func (h *Handler) TestConnectionHandler(c *gin.Context) {
 		return
 	}
 
-	_, err := h.clientset.CoreV1().Namespaces().List(c.Request.Context(), metav1.ListOptions{})
+	_, err := clientset.CoreV1().Namespaces().List(c.Request.Context(), metav1.ListOptions{})
 	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
 			"connected": false,
@@ -103,21 +254,19 @@ func (h *Handler) TestConnectionHandler(c *gin.Context) {
 func (h *Handler) GetApplicationsHandler(c *gin.Context) {
 	namespace := c.DefaultQuery("namespace", "all")
 
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
 		return
 	}
 
 	// Получаем деплойменты
-	var deployments *appsv1.DeploymentList
-	var err error
-
+	listNamespace := namespace
 	if namespace == "all" {
-		deployments, err = h.clientset.AppsV1().Deployments("").List(c.Request.Context(), metav1.ListOptions{})
-	} else {
-		deployments, err = h.clientset.AppsV1().Deployments(namespace).List(c.Request.Context(), metav1.ListOptions{})
+		listNamespace = ""
 	}
 
+	deployments, err := h.resources(c).ListDeployments(c.Request.Context(), listNamespace, liveRead(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -149,21 +298,47 @@ func (h *Handler) GetApplicationsHandler(c *gin.Context) {
 		})
 	}
 
+	// Получаем StatefulSet'ы
+	statefulSets, err := h.resources(c).ListStatefulSets(c.Request.Context(), listNamespace, liveRead(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, sts := range statefulSets.Items {
+		totalReplicas := statefulSetReplicas(&sts)
+
+		applications = append(applications, gin.H{
+			"name":        sts.Name,
+			"namespace":   sts.Namespace,
+			"type":        "StatefulSet",
+			"instances":   totalReplicas,
+			"ready":       fmt.Sprintf("%d/%d", sts.Status.ReadyReplicas, totalReplicas),
+			"ready_count": sts.Status.ReadyReplicas,
+			"total_count": totalReplicas,
+			"age":         time.Since(sts.CreationTimestamp.Time).Round(time.Second).String(),
+			"labels":      sts.Labels,
+			"strategy":    string(sts.Spec.UpdateStrategy.Type),
+		})
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"namespace":    namespace,
 		"count":        len(applications),
 		"applications": applications,
+		"source":       readSource(c),
 	})
 }
 
 // GetNamespacesHandler - Обработчик для получения списка namespace
 func (h *Handler) GetNamespacesHandler(c *gin.Context) {
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
 		return
 	}
 
-	namespaces, err := h.clientset.CoreV1().Namespaces().List(c.Request.Context(), metav1.ListOptions{})
+	namespaces, err := h.resources(c).ListNamespaces(c.Request.Context(), liveRead(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -186,12 +361,13 @@ func (h *Handler) GetNamespacesHandler(c *gin.Context) {
 
 // GetNodesHandler - Обработчик для получения списка нод
 func (h *Handler) GetNodesHandler(c *gin.Context) {
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
 		return
 	}
 
-	nodes, err := h.clientset.CoreV1().Nodes().List(c.Request.Context(), metav1.ListOptions{})
+	nodes, err := h.resources(c).ListNodes(c.Request.Context(), liveRead(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,508 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"k8s-manager/internal/k8s"
+)
+
+// Параметры HTTP-пробы маршрута
+const (
+	probeTimeout     = 10 * time.Second
+	probeBodyPreview = 512 // байт тела ответа в результате
+)
+
+// ingressClassAnnotation - устаревший способ указать класс Ingress
+const ingressClassAnnotation = "kubernetes.io/ingress.class"
+
+// backendIndex - сервисы и их EndpointSlice для сопоставления маршрутов с бэкендами.
+// Namespace загружаются по мере обращения (бэкенд HTTPRoute может быть в другом namespace).
+type backendIndex struct {
+	ctx       context.Context
+	resources *k8s.ResourceCache
+	live      bool
+	loaded    map[string]bool
+	services  map[string]*corev1.Service
+	endpoints map[string]*k8s.ServiceEndpoints
+}
+
+func (h *Handler) newBackendIndex(c *gin.Context) *backendIndex {
+	return &backendIndex{
+		ctx:       c.Request.Context(),
+		resources: h.resources(c),
+		live:      liveRead(c),
+		loaded:    make(map[string]bool),
+		services:  make(map[string]*corev1.Service),
+		endpoints: make(map[string]*k8s.ServiceEndpoints),
+	}
+}
+
+// load - сервисы и endpoints namespace ("" - все namespace)
+func (idx *backendIndex) load(namespace string) {
+	if idx.loaded[namespace] || idx.loaded[""] {
+		return
+	}
+	idx.loaded[namespace] = true
+
+	services, err := idx.resources.ListServices(idx.ctx, namespace, idx.live)
+	if err != nil {
+		log.Printf("⚠️  Failed to list services in %q: %v", namespace, err)
+		return
+	}
+	for i := range services.Items {
+		svc := &services.Items[i]
+		idx.services[svc.Namespace+"/"+svc.Name] = svc
+	}
+
+	slices, err := idx.resources.ListEndpointSlices(idx.ctx, namespace, idx.live)
+	if err != nil {
+		log.Printf("⚠️  Failed to list endpoint slices in %q: %v", namespace, err)
+		return
+	}
+	for key, endpoints := range k8s.IndexEndpointSlices(slices.Items) {
+		idx.endpoints[key] = endpoints
+	}
+}
+
+// servicePort - порт сервиса по номеру или имени
+func servicePort(svc *corev1.Service, port string) (*corev1.ServicePort, bool) {
+	for i := range svc.Spec.Ports {
+		p := &svc.Spec.Ports[i]
+		if p.Name == port || strconv.Itoa(int(p.Port)) == port {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// backend - бэкенд маршрута: сервис, порт, число готовых адресов и поды за ними.
+// port - номер или имя порта сервиса; пустой порт допустим, если у сервиса он один.
+func (idx *backendIndex) backend(namespace, service, port string) gin.H {
+	idx.load(namespace)
+
+	result := gin.H{
+		"service":   service,
+		"namespace": namespace,
+		"port":      port,
+		"exists":    false,
+		"ready":     0,
+		"notReady":  0,
+		"pods":      []string{},
+	}
+	warnings := []string{}
+
+	svc, exists := idx.services[namespace+"/"+service]
+	if !exists {
+		result["warnings"] = append(warnings, fmt.Sprintf("service %s/%s not found", namespace, service))
+		return result
+	}
+	result["exists"] = true
+	result["type"] = string(svc.Spec.Type)
+
+	if svc.Spec.Type == corev1.ServiceTypeExternalName {
+		result["externalName"] = svc.Spec.ExternalName
+		result["warnings"] = warnings
+		return result
+	}
+
+	if port == "" && len(svc.Spec.Ports) == 1 {
+		port = strconv.Itoa(int(svc.Spec.Ports[0].Port))
+		result["port"] = port
+	}
+	if p, ok := servicePort(svc, port); ok {
+		result["targetPort"] = p.TargetPort.String()
+	} else {
+		warnings = append(warnings, fmt.Sprintf("port %s not found on service %s", port, service))
+	}
+
+	if endpoints := idx.endpoints[namespace+"/"+service]; endpoints != nil {
+		result["ready"] = endpoints.Ready
+		result["notReady"] = endpoints.NotReady
+		result["pods"] = endpoints.ReadyPods()
+		if endpoints.Ready == 0 {
+			warnings = append(warnings, "no ready endpoints")
+		}
+	} else {
+		warnings = append(warnings, "no endpoints")
+	}
+
+	result["warnings"] = warnings
+	return result
+}
+
+// ingressBackendPort - порт бэкенда Ingress в виде строки (номер или имя)
+func ingressBackendPort(backend *networkingv1.IngressServiceBackend) string {
+	if backend.Port.Name != "" {
+		return backend.Port.Name
+	}
+	if backend.Port.Number != 0 {
+		return strconv.Itoa(int(backend.Port.Number))
+	}
+	return ""
+}
+
+// ingressClass - класс Ingress из spec.ingressClassName или устаревшей аннотации
+func ingressClass(ing *networkingv1.Ingress) string {
+	if ing.Spec.IngressClassName != nil {
+		return *ing.Spec.IngressClassName
+	}
+	return ing.Annotations[ingressClassAnnotation]
+}
+
+// ingressAddresses - внешние адреса из status.loadBalancer
+func ingressAddresses(ing *networkingv1.Ingress) []string {
+	addresses := []string{}
+	for _, lb := range ing.Status.LoadBalancer.Ingress {
+		if lb.IP != "" {
+			addresses = append(addresses, lb.IP)
+		}
+		if lb.Hostname != "" {
+			addresses = append(addresses, lb.Hostname)
+		}
+	}
+	return addresses
+}
+
+// ingressURL - адрес маршрута: https, если host есть в tls
+func ingressURL(ing *networkingv1.Ingress, host, path string) string {
+	scheme := "http"
+	for _, tls := range ing.Spec.TLS {
+		for _, tlsHost := range tls.Hosts {
+			if tlsHost == host {
+				scheme = "https"
+			}
+		}
+	}
+	if host == "" {
+		addresses := ingressAddresses(ing)
+		if len(addresses) == 0 {
+			return ""
+		}
+		host = addresses[0]
+	}
+	if path == "" {
+		path = "/"
+	}
+	return scheme + "://" + host + path
+}
+
+// ingressRoutes - строки host + path -> бэкенд, включая defaultBackend
+func ingressRoutes(ing *networkingv1.Ingress, idx *backendIndex) []gin.H {
+	routes := []gin.H{}
+
+	addRoute := func(host, path, pathType string, backend *networkingv1.IngressBackend) {
+		route := gin.H{
+			"host":     host,
+			"path":     path,
+			"pathType": pathType,
+			"url":      ingressURL(ing, host, path),
+		}
+		switch {
+		case backend.Service != nil:
+			route["backend"] = idx.backend(ing.Namespace, backend.Service.Name, ingressBackendPort(backend.Service))
+		case backend.Resource != nil:
+			route["backend"] = gin.H{
+				"resource": backend.Resource.Kind + "/" + backend.Resource.Name,
+				"warnings": []string{"resource backends are not resolved"},
+			}
+		}
+		routes = append(routes, route)
+	}
+
+	if ing.Spec.DefaultBackend != nil {
+		addRoute("*", "", "default", ing.Spec.DefaultBackend)
+	}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for i := range rule.HTTP.Paths {
+			path := &rule.HTTP.Paths[i]
+			pathType := ""
+			if path.PathType != nil {
+				pathType = string(*path.PathType)
+			}
+			addRoute(rule.Host, path.Path, pathType, &path.Backend)
+		}
+	}
+
+	return routes
+}
+
+// GetIngressesHandler - Ingress с разбором host/path -> сервис -> готовые поды (по EndpointSlice).
+// ?namespace=default|all&live=true
+func (h *Handler) GetIngressesHandler(c *gin.Context) {
+	namespace := c.DefaultQuery("namespace", "default")
+	listNamespace := namespace
+	if namespace == "all" {
+		listNamespace = ""
+	}
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	ingresses, err := clientset.NetworkingV1().Ingresses(listNamespace).List(c.Request.Context(), metav1.ListOptions{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	idx := h.newBackendIndex(c)
+	idx.load(listNamespace)
+
+	result := []gin.H{}
+	for i := range ingresses.Items {
+		ing := &ingresses.Items[i]
+
+		tlsHosts := []string{}
+		for _, tls := range ing.Spec.TLS {
+			tlsHosts = append(tlsHosts, tls.Hosts...)
+		}
+
+		result = append(result, gin.H{
+			"name":      ing.Name,
+			"namespace": ing.Namespace,
+			"class":     ingressClass(ing),
+			"addresses": ingressAddresses(ing),
+			"tlsHosts":  tlsHosts,
+			"routes":    ingressRoutes(ing, idx),
+			"age":       time.Since(ing.CreationTimestamp.Time).Round(time.Second).String(),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"namespace": namespace,
+		"count":     len(result),
+		"ingresses": result,
+		"source":    readSource(c),
+	})
+}
+
+// GetIngressClassesHandler - классы Ingress и класс по умолчанию
+func (h *Handler) GetIngressClassesHandler(c *gin.Context) {
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	classes, err := clientset.NetworkingV1().IngressClasses().List(c.Request.Context(), metav1.ListOptions{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := []gin.H{}
+	for _, class := range classes.Items {
+		info := gin.H{
+			"name":       class.Name,
+			"controller": class.Spec.Controller,
+			"default":    class.Annotations[networkingv1.AnnotationIsDefaultIngressClass] == "true",
+			"age":        time.Since(class.CreationTimestamp.Time).Round(time.Second).String(),
+		}
+		if params := class.Spec.Parameters; params != nil {
+			info["parameters"] = params
+		}
+		result = append(result, info)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":   len(result),
+		"classes": result,
+	})
+}
+
+// serviceIngresses - Ingress namespace, которые ссылаются на сервис (обратный поиск)
+func serviceIngresses(ctx context.Context, clientset *kubernetes.Clientset, namespace, service string) []gin.H {
+	result := []gin.H{}
+
+	ingresses, err := clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("⚠️  Failed to list ingresses in %s: %v", namespace, err)
+		return result
+	}
+
+	for i := range ingresses.Items {
+		ing := &ingresses.Items[i]
+
+		routes := []string{}
+		if backend := ing.Spec.DefaultBackend; backend != nil && backend.Service != nil && backend.Service.Name == service {
+			routes = append(routes, "* (default) -> "+ingressBackendPort(backend.Service))
+		}
+		for _, rule := range ing.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				if path.Backend.Service != nil && path.Backend.Service.Name == service {
+					host := rule.Host
+					if host == "" {
+						host = "*"
+					}
+					routes = append(routes, host+path.Path+" -> "+ingressBackendPort(path.Backend.Service))
+				}
+			}
+		}
+
+		if len(routes) > 0 {
+			result = append(result, gin.H{
+				"name":   ing.Name,
+				"class":  ingressClass(ing),
+				"routes": routes,
+			})
+		}
+	}
+	return result
+}
+
+// probeService - HTTP GET к сервису через прокси API-сервера: запрос идет из сети кластера,
+// минуя ingress-контроллер (Host и TLS ingress не проверяются)
+func probeService(ctx context.Context, clientset *kubernetes.Clientset, namespace, service, port, path string) gin.H {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	scheme := ""
+	if port == "443" || port == "https" {
+		scheme = "https"
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	probe := gin.H{
+		"target":     fmt.Sprintf("%s/%s:%s", namespace, service, port),
+		"path":       path,
+		"statusCode": 0,
+		"ok":         false,
+	}
+
+	// RESTClient превращает ответы не 2xx в ошибки без кода - запрос выполняем сами
+	restClient, ok := clientset.CoreV1().RESTClient().(*rest.RESTClient)
+	if !ok {
+		probe["error"] = "unexpected REST client"
+		return probe
+	}
+	proxyURL := restClient.Get().
+		Namespace(namespace).
+		Resource("services").
+		Name(utilnet.JoinSchemeNamePort(scheme, service, port)).
+		SubResource("proxy").
+		Suffix(path).
+		URL()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, proxyURL.String(), nil)
+	if err != nil {
+		probe["error"] = err.Error()
+		return probe
+	}
+
+	start := time.Now()
+	resp, err := restClient.Client.Do(req)
+	probe["latency"] = time.Since(start).Round(time.Millisecond).String()
+	if err != nil {
+		probe["error"] = err.Error()
+		return probe
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, probeBodyPreview))
+	probe["statusCode"] = resp.StatusCode
+	probe["ok"] = resp.StatusCode >= 200 && resp.StatusCode < 400
+	probe["contentType"] = resp.Header.Get("Content-Type")
+	probe["body"] = string(body)
+	return probe
+}
+
+// matchIngressPath - путь правила подходит к запрошенному (Exact или по префиксу)
+func matchIngressPath(rulePath *networkingv1.HTTPIngressPath, path string) bool {
+	if rulePath.PathType != nil && *rulePath.PathType == networkingv1.PathTypeExact {
+		return rulePath.Path == path
+	}
+	prefix := strings.TrimSuffix(rulePath.Path, "/")
+	return path == prefix || strings.HasPrefix(path, prefix+"/") || prefix == ""
+}
+
+// ProbeIngressHandler - HTTP-проба маршрута Ingress изнутри кластера.
+// ?host=&path= - маршрут выбирается как у контроллера: host, затем самый длинный подходящий path
+func (h *Handler) ProbeIngressHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	host := c.Query("host")
+	path := c.Query("path")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	ing, err := clientset.NetworkingV1().Ingresses(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	var backend *networkingv1.IngressServiceBackend
+	matchedHost, matchedPath := "", ""
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil || (host != "" && rule.Host != host) {
+			continue
+		}
+		for i := range rule.HTTP.Paths {
+			rulePath := &rule.HTTP.Paths[i]
+			if rulePath.Backend.Service == nil {
+				continue
+			}
+			if path != "" && !matchIngressPath(rulePath, path) {
+				continue
+			}
+			if backend == nil || len(rulePath.Path) > len(matchedPath) {
+				backend = rulePath.Backend.Service
+				matchedHost, matchedPath = rule.Host, rulePath.Path
+			}
+		}
+		if backend != nil {
+			break
+		}
+	}
+	if backend == nil && ing.Spec.DefaultBackend != nil {
+		backend = ing.Spec.DefaultBackend.Service
+	}
+	if backend == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No service backend matches host and path"})
+		return
+	}
+
+	if path == "" {
+		path = matchedPath
+	}
+
+	port := ingressBackendPort(backend)
+	idx := h.newBackendIndex(c)
+	c.JSON(http.StatusOK, gin.H{
+		"ingress": name,
+		"host":    matchedHost,
+		"rule":    matchedPath,
+		"url":     ingressURL(ing, matchedHost, path),
+		"backend": idx.backend(namespace, backend.Name, port),
+		"probe":   probeService(c.Request.Context(), clientset, namespace, backend.Name, port, path),
+	})
+}
@@ -0,0 +1,493 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+func (h *Handler) GetJobsHandler(c *gin.Context) {
+	namespace := c.DefaultQuery("namespace", "market")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	jobs, err := h.resources(c).ListJobs(c.Request.Context(), namespace, liveRead(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var result []gin.H
+	for _, job := range jobs.Items {
+		result = append(result, jobSummary(&job))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"namespace": namespace,
+		"count":     len(jobs.Items),
+		"jobs":      result,
+		"source":    readSource(c),
+	})
+}
+
+func (h *Handler) GetCronJobsHandler(c *gin.Context) {
+	namespace := c.DefaultQuery("namespace", "market")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	cronJobs, err := h.resources(c).ListCronJobs(c.Request.Context(), namespace, liveRead(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var result []gin.H
+	for _, cj := range cronJobs.Items {
+		lastSchedule := ""
+		if cj.Status.LastScheduleTime != nil {
+			lastSchedule = cj.Status.LastScheduleTime.Format(time.RFC3339)
+		}
+		lastSuccess := ""
+		if cj.Status.LastSuccessfulTime != nil {
+			lastSuccess = cj.Status.LastSuccessfulTime.Format(time.RFC3339)
+		}
+
+		result = append(result, gin.H{
+			"name":              cj.Name,
+			"namespace":         cj.Namespace,
+			"schedule":          cj.Spec.Schedule,
+			"timezone":          cj.Spec.TimeZone,
+			"suspended":         cj.Spec.Suspend != nil && *cj.Spec.Suspend,
+			"active":            len(cj.Status.Active),
+			"concurrency":       string(cj.Spec.ConcurrencyPolicy),
+			"last_schedule":     lastSchedule,
+			"last_successful":   lastSuccess,
+			"history":           h.apiLink(c, "/cronjob/history/%s/%s", cj.Namespace, cj.Name),
+			"age":               time.Since(cj.CreationTimestamp.Time).Round(time.Second).String(),
+			"labels":            cj.Labels,
+			"successful_limit":  cj.Spec.SuccessfulJobsHistoryLimit,
+			"failed_limit":      cj.Spec.FailedJobsHistoryLimit,
+			"starting_deadline": cj.Spec.StartingDeadlineSeconds,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"namespace": namespace,
+		"count":     len(cronJobs.Items),
+		"cronjobs":  result,
+		"source":    readSource(c),
+	})
+}
+
+// GetCronJobHistoryHandler - запуски CronJob (Job'ы, которыми он владеет)
+func (h *Handler) GetCronJobHistoryHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	cj, err := clientset.BatchV1().CronJobs(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	jobs, err := h.resources(c).ListJobs(c.Request.Context(), namespace, liveRead(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var owned []batchv1.Job
+	for _, job := range jobs.Items {
+		if ref := controllerRef(job.OwnerReferences); ref != nil && ref.UID == cj.UID {
+			owned = append(owned, job)
+		}
+	}
+
+	// Новые запуски первыми
+	sort.Slice(owned, func(i, j int) bool {
+		return owned[j].CreationTimestamp.Before(&owned[i].CreationTimestamp)
+	})
+
+	runs := []gin.H{}
+	for _, job := range owned {
+		run := jobSummary(&job)
+		run["pods"] = h.apiLink(c, "/job/pods/%s/%s", job.Namespace, job.Name)
+		runs = append(runs, run)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cronjob":   name,
+		"namespace": namespace,
+		"count":     len(runs),
+		"runs":      runs,
+	})
+}
+
+// GetJobPodsHandler - поды запуска Job со ссылками на логи
+func (h *Handler) GetJobPodsHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	job, err := clientset.BatchV1().Jobs(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(job.Spec.Selector)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	pods, err := clientset.CoreV1().Pods(namespace).List(c.Request.Context(), metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := []gin.H{}
+	for _, pod := range pods.Items {
+		result = append(result, gin.H{
+			"name":      pod.Name,
+			"namespace": pod.Namespace,
+			"status":    pod.Status.Phase,
+			"restarts":  getRestartCount(pod),
+			"node":      pod.Spec.NodeName,
+			"age":       time.Since(pod.CreationTimestamp.Time).Round(time.Second).String(),
+			"exit_code": podExitCode(&pod),
+			"logs": gin.H{
+				"view":     h.apiLink(c, "/logs/%s/%s", pod.Namespace, pod.Name),
+				"download": h.apiLink(c, "/logs/download/%s/%s", pod.Namespace, pod.Name),
+				"stream":   h.apiLink(c, "/logs/stream/%s/%s", pod.Namespace, pod.Name),
+			},
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"job":       name,
+		"namespace": namespace,
+		"status":    jobStatus(job),
+		"count":     len(result),
+		"pods":      result,
+	})
+}
+
+// TriggerCronJobHandler - запуск Job из шаблона CronJob (kubectl create job --from=cronjob/x)
+func (h *Handler) TriggerCronJobHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	cj, err := clientset.BatchV1().CronJobs(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "CronJob not found: " + err.Error()})
+		return
+	}
+
+	// Имя Job: <cronjob>-manual-<suffix>, не длиннее 63 символов
+	prefix := cj.Name
+	if len(prefix) > 49 {
+		prefix = prefix[:49]
+	}
+	jobName := fmt.Sprintf("%s-manual-%s", prefix, rand.String(5))
+
+	annotations := map[string]string{"cronjob.kubernetes.io/instantiate": "manual"}
+	for k, v := range cj.Spec.JobTemplate.Annotations {
+		annotations[k] = v
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        jobName,
+			Namespace:   namespace,
+			Labels:      cj.Spec.JobTemplate.Labels,
+			Annotations: annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(cj, batchv1.SchemeGroupVersion.WithKind("CronJob")),
+			},
+		},
+		Spec: cj.Spec.JobTemplate.Spec,
+	}
+
+	created, err := clientset.BatchV1().Jobs(namespace).Create(c.Request.Context(), job, metav1.CreateOptions{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   fmt.Sprintf("Job %s created from CronJob %s", created.Name, name),
+		"job":       created.Name,
+		"cronjob":   name,
+		"namespace": namespace,
+		"pods":      h.apiLink(c, "/job/pods/%s/%s", namespace, created.Name),
+	})
+}
+
+func (h *Handler) SuspendCronJobHandler(c *gin.Context) {
+	h.setCronJobSuspend(c, true)
+}
+
+func (h *Handler) ResumeCronJobHandler(c *gin.Context) {
+	h.setCronJobSuspend(c, false)
+}
+
+func (h *Handler) setCronJobSuspend(c *gin.Context, suspend bool) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	cj, err := clientset.BatchV1().CronJobs(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "CronJob not found: " + err.Error()})
+		return
+	}
+
+	cj.Spec.Suspend = &suspend
+	_, err = clientset.BatchV1().CronJobs(namespace).Update(c.Request.Context(), cj, metav1.UpdateOptions{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	action := "resumed"
+	if suspend {
+		action = "suspended"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   fmt.Sprintf("CronJob %s %s", name, action),
+		"cronjob":   name,
+		"namespace": namespace,
+		"suspended": suspend,
+	})
+}
+
+func (h *Handler) DeleteJobHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	propagation, ok := parsePropagationPolicy(c.DefaultQuery("propagation", "Background"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid propagation policy (Background, Foreground, Orphan)"})
+		return
+	}
+
+	err := clientset.BatchV1().Jobs(namespace).Delete(c.Request.Context(), name, metav1.DeleteOptions{
+		PropagationPolicy: &propagation,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Job deleted successfully",
+		"job":         name,
+		"namespace":   namespace,
+		"propagation": string(propagation),
+	})
+}
+
+// DeleteFinishedJobsHandler - удаление завершенных (Complete/Failed) Job'ов namespace.
+// ?cronjob=name ограничивает удаление запусками одного CronJob.
+func (h *Handler) DeleteFinishedJobsHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	cronJobName := c.Query("cronjob")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	propagation, ok := parsePropagationPolicy(c.DefaultQuery("propagation", "Background"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid propagation policy (Background, Foreground, Orphan)"})
+		return
+	}
+
+	jobs, err := clientset.BatchV1().Jobs(namespace).List(c.Request.Context(), metav1.ListOptions{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	deleted := []string{}
+	failed := []gin.H{}
+	for _, job := range jobs.Items {
+		if !jobFinished(&job) {
+			continue
+		}
+		if cronJobName != "" {
+			ref := controllerRef(job.OwnerReferences)
+			if ref == nil || ref.Kind != "CronJob" || ref.Name != cronJobName {
+				continue
+			}
+		}
+
+		err := clientset.BatchV1().Jobs(namespace).Delete(c.Request.Context(), job.Name, metav1.DeleteOptions{
+			PropagationPolicy: &propagation,
+		})
+		if err != nil {
+			failed = append(failed, gin.H{"job": job.Name, "error": err.Error()})
+			continue
+		}
+		deleted = append(deleted, job.Name)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     fmt.Sprintf("Deleted %d finished jobs", len(deleted)),
+		"namespace":   namespace,
+		"deleted":     deleted,
+		"failed":      failed,
+		"propagation": string(propagation),
+	})
+}
+
+func parsePropagationPolicy(value string) (metav1.DeletionPropagation, bool) {
+	switch metav1.DeletionPropagation(value) {
+	case metav1.DeletePropagationBackground, metav1.DeletePropagationForeground, metav1.DeletePropagationOrphan:
+		return metav1.DeletionPropagation(value), true
+	}
+	return "", false
+}
+
+// jobSummary - краткая информация о Job для списков
+func jobSummary(job *batchv1.Job) gin.H {
+	completions := int32(1)
+	if job.Spec.Completions != nil {
+		completions = *job.Spec.Completions
+	}
+
+	startTime := ""
+	if job.Status.StartTime != nil {
+		startTime = job.Status.StartTime.Format(time.RFC3339)
+	}
+	completionTime := ""
+	if job.Status.CompletionTime != nil {
+		completionTime = job.Status.CompletionTime.Format(time.RFC3339)
+	}
+
+	owner := ""
+	if ref := controllerRef(job.OwnerReferences); ref != nil && ref.Kind == "CronJob" {
+		owner = ref.Name
+	}
+
+	return gin.H{
+		"name":        job.Name,
+		"namespace":   job.Namespace,
+		"status":      jobStatus(job),
+		"completions": fmt.Sprintf("%d/%d", job.Status.Succeeded, completions),
+		"succeeded":   job.Status.Succeeded,
+		"failed":      job.Status.Failed,
+		"active":      job.Status.Active,
+		"started":     startTime,
+		"completed":   completionTime,
+		"duration":    jobDuration(job),
+		"cronjob":     owner,
+		"age":         time.Since(job.CreationTimestamp.Time).Round(time.Second).String(),
+		"labels":      job.Labels,
+	}
+}
+
+func jobStatus(job *batchv1.Job) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return "Complete"
+		case batchv1.JobFailed:
+			return "Failed"
+		case batchv1.JobSuspended:
+			return "Suspended"
+		}
+	}
+	if job.Status.Active > 0 {
+		return "Running"
+	}
+	return "Pending"
+}
+
+func jobFinished(job *batchv1.Job) bool {
+	status := jobStatus(job)
+	return status == "Complete" || status == "Failed"
+}
+
+// jobDuration - время выполнения; для активных Job - с момента старта.
+// У Failed Job нет CompletionTime - конец берется из перехода условия Failed.
+func jobDuration(job *batchv1.Job) string {
+	if job.Status.StartTime == nil {
+		return ""
+	}
+	end := time.Now()
+	if job.Status.CompletionTime != nil {
+		end = job.Status.CompletionTime.Time
+	} else {
+		for _, condition := range job.Status.Conditions {
+			if condition.Status == corev1.ConditionTrue && !condition.LastTransitionTime.IsZero() &&
+				(condition.Type == batchv1.JobFailed || condition.Type == batchv1.JobComplete) {
+				end = condition.LastTransitionTime.Time
+			}
+		}
+	}
+	return end.Sub(job.Status.StartTime.Time).Round(time.Second).String()
+}
+
+// podExitCode - код завершения первого завершившегося контейнера
+func podExitCode(pod *corev1.Pod) *int32 {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil {
+			return &status.State.Terminated.ExitCode
+		}
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"k8s-manager/internal/k8s"
+)
+
+// StartAggregatedLogStreamHandler - логи всех подов по селектору (как stern) через WebSocket или SSE.
+// ?selector=app=web | ?deployment=name | ?statefulset=name, ?container=regex, плюс параметры логов
+func (h *Handler) StartAggregatedLogStreamHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	selector, err := h.resolveLogSelector(c, clientset, namespace)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	logReq, ok := parseLogRequest(c, 10, true)
+	if !ok {
+		return
+	}
+
+	// container - регулярное выражение по имени контейнера
+	var containerFilter *regexp.Regexp
+	if logReq.Options.Container != "" {
+		containerFilter, err = regexp.Compile(logReq.Options.Container)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container regex: " + err.Error()})
+			return
+		}
+		logReq.Options.Container = ""
+	}
+
+	cluster := h.cluster(c)
+
+	streamID := fmt.Sprintf("%s-%s-selector-%d", cluster.Name, namespace, time.Now().UnixNano())
+
+	stream := newLogStream(streamID, parseLogBufferSize(c))
+	stream.Cluster = cluster.Name
+	stream.Namespace = namespace
+	stream.Selector = selector.String()
+	stream.AllContainers = containerFilter == nil
+	stream.Options = logReq.Options
+	stream.Filter = logReq.Filter
+	stream.Follow = true
+	if since, ok := logResumePoint(c); ok {
+		stream.ResumeFrom = since
+	}
+
+	sink, err := openEventSink(c)
+	if err != nil {
+		log.Printf("WebSocket upgrade error: %v", err)
+		return
+	}
+
+	manager := GetLogStreamManager()
+	manager.AddStream(stream)
+	done := stream.serve(sink)
+
+	defer func() {
+		manager.RemoveStream(streamID)
+		stream.Stop()
+		<-done
+		log.Printf("Aggregated log stream stopped: %s [%s]", namespace, stream.Selector)
+	}()
+
+	log.Printf("Aggregated log stream started: %s [%s]", namespace, stream.Selector)
+
+	stream.Send(LogMessage{
+		Type:    "info",
+		Message: fmt.Sprintf("Log stream started for pods in %s matching %q", namespace, stream.Selector),
+		Data:    gin.H{"id": streamID},
+		Time:    time.Now().Format(time.RFC3339),
+	})
+
+	if err := h.streamSelectorLogs(stream, cluster.Clientset, containerFilter); err != nil {
+		stream.Send(LogMessage{
+			Type:    "error",
+			Message: fmt.Sprintf("Error streaming logs: %v", err),
+			Time:    time.Now().Format(time.RFC3339),
+		})
+	}
+}
+
+// resolveLogSelector - селектор из ?selector= или из спецификации Deployment/StatefulSet
+func (h *Handler) resolveLogSelector(c *gin.Context, clientset *kubernetes.Clientset, namespace string) (labels.Selector, error) {
+	return workloadSelector(c.Request.Context(), clientset, namespace, c.Query("selector"), c.Query("deployment"), c.Query("statefulset"))
+}
+
+// workloadSelector - селектор подов: явный или из спецификации Deployment/StatefulSet
+func workloadSelector(ctx context.Context, clientset *kubernetes.Clientset, namespace, selector, deployment, statefulset string) (labels.Selector, error) {
+	var labelSelector *metav1.LabelSelector
+
+	switch {
+	case deployment != "":
+		dep, err := clientset.AppsV1().Deployments(namespace).Get(ctx, deployment, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		labelSelector = dep.Spec.Selector
+
+	case statefulset != "":
+		sts, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, statefulset, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		labelSelector = sts.Spec.Selector
+
+	case selector != "":
+		return labels.Parse(selector)
+
+	default:
+		return nil, fmt.Errorf("one of selector, deployment or statefulset is required")
+	}
+
+	result, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	if result.Empty() {
+		return nil, fmt.Errorf("workload has an empty selector")
+	}
+	return result, nil
+}
+
+// streamSelectorLogs - tail всех контейнеров подов по селектору; новые поды подключаются по watch,
+// после рестарта контейнера чтение продолжается с последней строки (см. k8s.PodLogFollower)
+func (h *Handler) streamSelectorLogs(stream *LogStream, clientset *kubernetes.Clientset, containerFilter *regexp.Regexp) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Отслеживаем закрытие WebSocket
+	go func() {
+		<-stream.StopChan
+		cancel()
+	}()
+
+	follower := &k8s.PodLogFollower{
+		Clientset: clientset,
+		Namespace: stream.Namespace,
+		Selector:  stream.Selector,
+		Options:   stream.Options,
+		Since:     stream.ResumeFrom,
+	}
+	if containerFilter != nil {
+		follower.ContainerFilter = containerFilter.MatchString
+	}
+
+	// Все события идут через один канал и дальше в очередь отправки стрима
+	events := make(chan k8s.PodLogEvent, stream.BufferSize)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- follower.Run(ctx, events)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case err := <-errChan:
+			return err
+
+		case event := <-events:
+			key := event.Pod + "/" + event.Container
+			msg := LogMessage{
+				Data: gin.H{"pod": event.Pod, "container": event.Container},
+				Time: time.Now().Format(time.RFC3339),
+			}
+
+			switch event.Type {
+			case k8s.PodLogJoined:
+				msg.Type = "info"
+				msg.Message = "+ " + key
+				msg.Data = gin.H{"pod": event.Pod, "container": event.Container, "event": "joined"}
+			case k8s.PodLogLeft:
+				msg.Type = "info"
+				msg.Message = "- " + key
+				msg.Data = gin.H{"pod": event.Pod, "container": event.Container, "event": "left"}
+			case k8s.PodLogWarning:
+				msg.Type = "warning"
+				msg.Message = fmt.Sprintf("Failed to tail %s: %v", key, event.Err)
+			default:
+				level, fields := analyzeLogLine(event.Text)
+				if !stream.Filter.Match(event.Text, level) {
+					continue
+				}
+
+				msg.ID = logEventID(event.Time)
+				msg.Type = "log"
+				msg.Message = fmt.Sprintf("[%s] %s", key, event.Text)
+				if stream.Options.Timestamps && !event.Time.IsZero() {
+					msg.Message = event.Time.Format(time.RFC3339Nano) + " " + msg.Message
+				}
+				msg.Level = level
+				msg.Fields = fields
+			}
+
+			if msg.Type == "log" {
+				stream.SendLog(msg)
+			} else {
+				stream.Send(msg)
+			}
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Нормализованные уровни логов
+const (
+	LogLevelError = "ERROR"
+	LogLevelWarn  = "WARN"
+	LogLevelInfo  = "INFO"
+	LogLevelDebug = "DEBUG"
+)
+
+// levelPattern - уровень в текстовой строке: "ERROR ...", "[warn]", "level=info"
+var levelPattern = regexp.MustCompile(`(?i)\b(fatal|panic|critical|crit|error|err|warning|warn|info|debug|trace)\b`)
+
+// jsonLevelKeys, jsonMessageKeys, jsonTraceKeys - поля распространенных JSON-логгеров (zap, logrus, slog, logback, serilog)
+var (
+	jsonLevelKeys   = []string{"level", "lvl", "severity", "log.level", "@l"}
+	jsonMessageKeys = []string{"msg", "message", "@m", "@mt"}
+	jsonTraceKeys   = []string{"trace_id", "traceId", "traceID", "trace.id", "dd.trace_id"}
+)
+
+// LogFilter - серверная фильтрация строк: include/exclude regex и уровни
+type LogFilter struct {
+	Include *regexp.Regexp
+	Exclude *regexp.Regexp
+	Levels  map[string]bool // пусто - любые уровни; "UNKNOWN" - строки без уровня
+}
+
+// LogEntry - разобранная строка лога для structured-ответов
+type LogEntry struct {
+	Time      string                 `json:"time,omitempty"`
+	Container string                 `json:"container,omitempty"`
+	Level     string                 `json:"level,omitempty"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+}
+
+// parseLogFilter - ?include=regex&exclude=regex&level=error,warn; nil, если фильтров нет
+func parseLogFilter(c *gin.Context) (*LogFilter, error) {
+	filter := &LogFilter{Levels: make(map[string]bool)}
+
+	if include := c.Query("include"); include != "" {
+		re, err := regexp.Compile(include)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include regex: %w", err)
+		}
+		filter.Include = re
+	}
+
+	if exclude := c.Query("exclude"); exclude != "" {
+		re, err := regexp.Compile(exclude)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude regex: %w", err)
+		}
+		filter.Exclude = re
+	}
+
+	if levels := c.Query("level"); levels != "" {
+		for _, level := range strings.Split(levels, ",") {
+			level = strings.TrimSpace(level)
+			if level == "" {
+				continue
+			}
+			if strings.EqualFold(level, "unknown") {
+				filter.Levels["UNKNOWN"] = true
+				continue
+			}
+			normalized := normalizeLevel(level)
+			if normalized == "" {
+				return nil, fmt.Errorf("unknown log level: %s", level)
+			}
+			filter.Levels[normalized] = true
+		}
+	}
+
+	if filter.Include == nil && filter.Exclude == nil && len(filter.Levels) == 0 {
+		return nil, nil
+	}
+	return filter, nil
+}
+
+// Match - проходит ли строка фильтр; nil-фильтр пропускает все
+func (f *LogFilter) Match(text, level string) bool {
+	if f == nil {
+		return true
+	}
+	if f.Include != nil && !f.Include.MatchString(text) {
+		return false
+	}
+	if f.Exclude != nil && f.Exclude.MatchString(text) {
+		return false
+	}
+	if len(f.Levels) > 0 {
+		if level == "" {
+			return f.Levels["UNKNOWN"]
+		}
+		return f.Levels[level]
+	}
+	return true
+}
+
+// analyzeLogLine - уровень строки и, для JSON-строк, поля level/msg/trace_id
+func analyzeLogLine(text string) (string, map[string]interface{}) {
+	trimmed := strings.TrimSpace(text)
+	if strings.HasPrefix(trimmed, "{") && strings.HasSuffix(trimmed, "}") {
+		var parsed map[string]interface{}
+		if err := json.Unmarshal([]byte(trimmed), &parsed); err == nil {
+			fields := make(map[string]interface{})
+
+			level := ""
+			if raw, ok := firstField(parsed, jsonLevelKeys); ok {
+				fields["level"] = raw
+				level = normalizeLevel(fmt.Sprint(raw))
+			}
+			if raw, ok := firstField(parsed, jsonMessageKeys); ok {
+				fields["msg"] = raw
+			}
+			if raw, ok := firstField(parsed, jsonTraceKeys); ok {
+				fields["trace_id"] = raw
+			}
+
+			// Уровня нет в полях - ищем в сообщении
+			if level == "" {
+				if msg, ok := fields["msg"].(string); ok {
+					level = detectLevel(msg)
+				}
+			}
+			return level, fields
+		}
+	}
+
+	return detectLevel(text), nil
+}
+
+// detectLevel - первый найденный в тексте уровень
+func detectLevel(text string) string {
+	match := levelPattern.FindString(text)
+	if match == "" {
+		return ""
+	}
+	return normalizeLevel(match)
+}
+
+// normalizeLevel - ERROR/WARN/INFO/DEBUG; для syslog-числа и неизвестных значений - по смыслу или ""
+func normalizeLevel(level string) string {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "fatal", "panic", "critical", "crit", "error", "err", "alert", "emerg", "emergency", "50", "60":
+		return LogLevelError
+	case "warning", "warn", "40":
+		return LogLevelWarn
+	case "info", "information", "notice", "30":
+		return LogLevelInfo
+	case "debug", "trace", "verbose", "10", "20":
+		return LogLevelDebug
+	}
+	return ""
+}
+
+func firstField(fields map[string]interface{}, keys []string) (interface{}, bool) {
+	for _, key := range keys {
+		if value, ok := fields[key]; ok && value != nil {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// filterLogLines - отбор строк фильтром и их разбор для structured-ответа
+func filterLogLines(lines []logLine, filter *LogFilter, withContainer bool) ([]logLine, []LogEntry) {
+	matched := make([]logLine, 0, len(lines))
+	entries := make([]LogEntry, 0, len(lines))
+
+	for _, line := range lines {
+		level, fields := analyzeLogLine(line.Text)
+		if !filter.Match(line.Text, level) {
+			continue
+		}
+		matched = append(matched, line)
+
+		entry := LogEntry{
+			Level:   level,
+			Message: line.Text,
+			Fields:  fields,
+		}
+		if !line.Time.IsZero() {
+			entry.Time = line.Time.Format(time.RFC3339Nano)
+		}
+		if withContainer {
+			entry.Container = line.Container
+		}
+		entries = append(entries, entry)
+	}
+
+	return matched, entries
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAnalyzeLogLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		level  string
+		fields map[string]interface{}
+	}{
+		{name: "plain error", text: "2024/01/01 ERROR connection refused", level: LogLevelError},
+		{name: "bracketed warn", text: "[warn] disk almost full", level: LogLevelWarn},
+		{name: "logfmt", text: `ts=1 level=info msg="started"`, level: LogLevelInfo},
+		{name: "fatal maps to error", text: "FATAL: out of memory", level: LogLevelError},
+		{name: "trace maps to debug", text: "TRACE entering handler", level: LogLevelDebug},
+		{name: "word inside identifier is ignored", text: "errors_total=0 terminated", level: ""},
+		{name: "no level", text: "GET /healthz 200", level: ""},
+		{
+			name:   "zap json",
+			text:   `{"level":"warn","ts":1,"msg":"slow query","trace_id":"abc"}`,
+			level:  LogLevelWarn,
+			fields: map[string]interface{}{"level": "warn", "msg": "slow query", "trace_id": "abc"},
+		},
+		{
+			name:   "pino numeric level",
+			text:   `{"level":50,"msg":"boom"}`,
+			level:  LogLevelError,
+			fields: map[string]interface{}{"level": float64(50), "msg": "boom"},
+		},
+		{
+			name:   "serilog keys",
+			text:   `{"@l":"Information","@m":"ready","traceId":"t1"}`,
+			level:  LogLevelInfo,
+			fields: map[string]interface{}{"level": "Information", "msg": "ready", "trace_id": "t1"},
+		},
+		{
+			name:   "json level taken from message",
+			text:   `{"message":"ERROR while saving"}`,
+			level:  LogLevelError,
+			fields: map[string]interface{}{"msg": "ERROR while saving"},
+		},
+		{
+			name:   "json null level is skipped",
+			text:   `{"level":null,"severity":"DEBUG","msg":"x"}`,
+			level:  LogLevelDebug,
+			fields: map[string]interface{}{"level": "DEBUG", "msg": "x"},
+		},
+		{name: "broken json falls back to text", text: `{"level":"info", error}`, level: LogLevelInfo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, fields := analyzeLogLine(tt.text)
+			if level != tt.level {
+				t.Errorf("level = %q, want %q", level, tt.level)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("fields = %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestLogFilterMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter *LogFilter
+		text   string
+		level  string
+		want   bool
+	}{
+		{name: "nil filter", filter: nil, text: "anything", want: true},
+		{name: "include hit", filter: &LogFilter{Include: regexp.MustCompile(`timeout`)}, text: "read timeout", want: true},
+		{name: "include miss", filter: &LogFilter{Include: regexp.MustCompile(`timeout`)}, text: "ok", want: false},
+		{name: "exclude wins", filter: &LogFilter{Include: regexp.MustCompile(`GET`), Exclude: regexp.MustCompile(`healthz`)}, text: "GET /healthz", want: false},
+		{name: "level hit", filter: &LogFilter{Levels: map[string]bool{LogLevelError: true}}, text: "x", level: LogLevelError, want: true},
+		{name: "level miss", filter: &LogFilter{Levels: map[string]bool{LogLevelError: true}}, text: "x", level: LogLevelInfo, want: false},
+		{name: "no level without unknown", filter: &LogFilter{Levels: map[string]bool{LogLevelError: true}}, text: "x", want: false},
+		{name: "no level with unknown", filter: &LogFilter{Levels: map[string]bool{"UNKNOWN": true}}, text: "x", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(tt.text, tt.level); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLogFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		query   string
+		levels  map[string]bool
+		isNil   bool
+		wantErr bool
+	}{
+		{name: "no filters", query: "", isNil: true},
+		{name: "levels normalized", query: "level=error,Warning,%20info,", levels: map[string]bool{LogLevelError: true, LogLevelWarn: true, LogLevelInfo: true}},
+		{name: "unknown level", query: "level=unknown", levels: map[string]bool{"UNKNOWN": true}},
+		{name: "bad level", query: "level=loud", wantErr: true},
+		{name: "bad include", query: "include=(", wantErr: true},
+		{name: "include only", query: "include=err", levels: map[string]bool{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/logs?"+tt.query, nil)
+
+			filter, err := parseLogFilter(c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.isNil {
+				if filter != nil {
+					t.Fatalf("filter = %+v, want nil", filter)
+				}
+				return
+			}
+			if !reflect.DeepEqual(filter.Levels, tt.levels) {
+				t.Errorf("levels = %v, want %v", filter.Levels, tt.levels)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"k8s-manager/internal/k8s"
+)
+
+// LogRequest - параметры чтения логов из query:
+// container, previous, sinceSeconds, sinceTime (RFC3339), timestamps, limitBytes, tail, all_containers,
+// фильтры include, exclude, level и structured=true для разобранных строк
+type LogRequest struct {
+	Options       corev1.PodLogOptions
+	AllContainers bool
+	Filter        *LogFilter
+	Structured    bool
+}
+
+// parseLogRequest - разбирает параметры логов; при ошибке пишет ответ 400
+func parseLogRequest(c *gin.Context, defaultTail int64, defaultTimestamps bool) (*LogRequest, bool) {
+	req := &LogRequest{
+		Options: corev1.PodLogOptions{
+			Container:  c.Query("container"),
+			Previous:   c.Query("previous") == "true",
+			Timestamps: defaultTimestamps,
+		},
+		AllContainers: c.Query("all_containers") == "true",
+		Structured:    c.Query("structured") == "true",
+	}
+
+	filter, err := parseLogFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	req.Filter = filter
+
+	if req.AllContainers && req.Options.Container != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "container and all_containers are mutually exclusive"})
+		return nil, false
+	}
+
+	if ts := c.Query("timestamps"); ts != "" {
+		req.Options.Timestamps = ts == "true"
+	}
+
+	// tail=all (или -1) - весь лог
+	tail := defaultTail
+	if tailStr := c.Query("tail"); tailStr != "" {
+		if tailStr == "all" {
+			tail = -1
+		} else if n, err := strconv.ParseInt(tailStr, 10, 64); err == nil {
+			tail = n
+		}
+	}
+	if tail >= 0 {
+		req.Options.TailLines = &tail
+	}
+
+	sinceSeconds := c.Query("sinceSeconds")
+	sinceTime := c.Query("sinceTime")
+	if sinceSeconds != "" && sinceTime != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sinceSeconds and sinceTime are mutually exclusive"})
+		return nil, false
+	}
+
+	if sinceSeconds != "" {
+		n, err := strconv.ParseInt(sinceSeconds, 10, 64)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sinceSeconds value"})
+			return nil, false
+		}
+		req.Options.SinceSeconds = &n
+	}
+
+	if sinceTime != "" {
+		t, err := time.Parse(time.RFC3339, sinceTime)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sinceTime value, expected RFC3339"})
+			return nil, false
+		}
+		since := metav1.NewTime(t)
+		req.Options.SinceTime = &since
+	}
+
+	if limitStr := c.Query("limitBytes"); limitStr != "" {
+		n, err := strconv.ParseInt(limitStr, 10, 64)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limitBytes value"})
+			return nil, false
+		}
+		req.Options.LimitBytes = &n
+	}
+
+	return req, true
+}
+
+// logLine - строка лога контейнера с временем из timestamps=true
+type logLine struct {
+	Time      time.Time
+	Container string
+	Text      string
+}
+
+// readLogLines - читает лог контейнера построчно; строки без метки получают время предыдущей
+func readLogLines(r io.Reader, container string) ([]logLine, error) {
+	scanner := bufio.NewScanner(r)
+	const maxCapacity = 1024 * 1024 // 1MB
+	scanner.Buffer(make([]byte, 64*1024), maxCapacity)
+
+	var lines []logLine
+	var last time.Time
+	for scanner.Scan() {
+		t, text, ok := k8s.SplitLogTimestamp(scanner.Text())
+		if ok {
+			last = t
+		} else {
+			t = last
+		}
+		lines = append(lines, logLine{Time: t, Container: container, Text: text})
+	}
+	return lines, scanner.Err()
+}
+
+// fetchAllContainerLogs - логи всех контейнеров пода, слитые по времени.
+// limitBytes и tail применяются к каждому контейнеру отдельно (так работает API).
+func fetchAllContainerLogs(ctx context.Context, clientset *kubernetes.Clientset, pod *corev1.Pod, opts corev1.PodLogOptions) ([]logLine, map[string]string) {
+	var merged []logLine
+	errors := make(map[string]string)
+
+	for _, container := range k8s.LogContainers(pod) {
+		containerOpts := opts
+		containerOpts.Container = container
+		containerOpts.Timestamps = true // для сортировки
+		containerOpts.Follow = false
+
+		stream, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &containerOpts).Stream(ctx)
+		if err != nil {
+			errors[container] = err.Error()
+			continue
+		}
+
+		lines, err := readLogLines(stream, container)
+		stream.Close()
+		if err != nil {
+			errors[container] = err.Error()
+		}
+		merged = append(merged, lines...)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Time.Before(merged[j].Time)
+	})
+
+	return merged, errors
+}
+
+// formatLogLines - строки через formatLogLine, по одной на строку текста
+func formatLogLines(lines []logLine, timestamps, withContainer bool) string {
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(formatLogLine(line, timestamps, withContainer))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// formatLogLine - "[container] текст" (или только текст), с меткой времени при timestamps=true
+func formatLogLine(line logLine, timestamps, withContainer bool) string {
+	text := line.Text
+	if withContainer {
+		text = fmt.Sprintf("[%s] %s", line.Container, text)
+	}
+	if timestamps && !line.Time.IsZero() {
+		text = line.Time.Format(time.RFC3339Nano) + " " + text
+	}
+	return text
+}
+
+// fetchPodLogs - логи для GET/Download: один контейнер или все контейнеры пода
+func fetchPodLogs(ctx context.Context, clientset *kubernetes.Clientset, namespace, podName string, req *LogRequest) (gin.H, error) {
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := gin.H{
+		"containers": k8s.LogContainers(pod),
+	}
+
+	if req.AllContainers {
+		lines, errors := fetchAllContainerLogs(ctx, clientset, pod, req.Options)
+		lines = applyLogFilter(result, lines, req, true)
+		result["logs"] = formatLogLines(lines, req.Options.Timestamps, true)
+		result["container"] = "*"
+		if len(errors) > 0 {
+			result["errors"] = errors
+		}
+		return result, nil
+	}
+
+	opts := req.Options
+	if opts.Container == "" {
+		opts.Container = defaultContainer(pod)
+	}
+	filtered := req.Filter != nil || req.Structured
+	if filtered {
+		// Метку kubelet отрезает readLogLines, а formatLogLines возвращает ее только при timestamps=true:
+		// без нее readLogLines отрезал бы RFC3339-время из текста самого приложения
+		opts.Timestamps = true
+	}
+
+	logs, err := clientset.CoreV1().Pods(namespace).GetLogs(podName, &opts).Do(ctx).Raw()
+	if err != nil {
+		return nil, err
+	}
+
+	result["container"] = opts.Container
+
+	if !filtered {
+		result["logs"] = string(logs)
+		return result, nil
+	}
+
+	lines, err := readLogLines(bytes.NewReader(logs), opts.Container)
+	if err != nil {
+		return nil, err
+	}
+	lines = applyLogFilter(result, lines, req, false)
+	result["logs"] = formatLogLines(lines, req.Options.Timestamps, false)
+	return result, nil
+}
+
+// applyLogFilter - фильтрует строки, добавляя в ответ счетчики и entries (structured=true)
+func applyLogFilter(result gin.H, lines []logLine, req *LogRequest, withContainer bool) []logLine {
+	if req.Filter == nil && !req.Structured {
+		return lines
+	}
+
+	matched, entries := filterLogLines(lines, req.Filter, withContainer)
+	result["total_lines"] = len(lines)
+	result["matched_lines"] = len(matched)
+	if req.Structured {
+		result["entries"] = entries
+	}
+	return matched
+}
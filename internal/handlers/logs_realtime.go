@@ -6,60 +6,54 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-)
-
-var (
-	upgrader = websocket.Upgrader{
-		CheckOrigin: func(r *http.Request) bool {
-			return true // Для разработки
-		},
-		ReadBufferSize:  1024,
-		WriteBufferSize: 1024,
-	}
 
-	// Хранилище активных WebSocket соединений
-	logStreams   = make(map[string]*LogStream)
-	logStreamsMu sync.RWMutex
+	"k8s-manager/internal/k8s"
 )
 
-type LogStream struct {
-	ID         string
-	Namespace  string
-	Pod        string
-	Conn       *websocket.Conn
-	StopChan   chan struct{}
-	BufferSize int
-	TailLines  int64
-	Follow     bool
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		return true // Для разработки
+	},
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
 }
 
 type LogMessage struct {
-	Type    string      `json:"type"` // log, error, info, warning
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
-	Time    string      `json:"time"`
+	ID      string                 `json:"id,omitempty"` // метка строки лога (RFC3339Nano) для возобновления через Last-Event-ID
+	Type    string                 `json:"type"`         // log, error, info, warning
+	Message string                 `json:"message"`
+	Level   string                 `json:"level,omitempty"`  // ERROR, WARN, INFO, DEBUG для строк лога
+	Fields  map[string]interface{} `json:"fields,omitempty"` // level, msg, trace_id для JSON-строк
+	Data    interface{}            `json:"data,omitempty"`
+	Time    string                 `json:"time"`
 }
 
 // GetLogStreamsHandler - Получение активных лог-стримов
 func (h *Handler) GetLogStreamsHandler(c *gin.Context) {
-	logStreamsMu.RLock()
-	defer logStreamsMu.RUnlock()
-
 	streams := make([]map[string]interface{}, 0)
-	for id, stream := range logStreams {
+	for _, stream := range GetLogStreamManager().GetStreams() {
 		streams = append(streams, map[string]interface{}{
-			"id":        id,
+			"id":        stream.ID,
+			"cluster":   stream.Cluster,
 			"pod":       stream.Pod,
 			"namespace": stream.Namespace,
+			"selector":  stream.Selector,
+			"container": stream.Container,
+			"all":       stream.AllContainers,
 			"follow":    stream.Follow,
+			"dropped":   stream.Dropped(),
+			"createdAt": stream.CreatedAt.Format(time.RFC3339),
 		})
 	}
 
@@ -69,60 +63,85 @@ func (h *Handler) GetLogStreamsHandler(c *gin.Context) {
 	})
 }
 
-// StartLogStreamHandler - Запуск лог-стрима через WebSocket
+// logResumePoint - время последней полученной клиентом строки из Last-Event-ID (или ?lastEventId=)
+func logResumePoint(c *gin.Context) (time.Time, bool) {
+	id := lastEventID(c)
+	if id == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, id)
+	return t, err == nil
+}
+
+// logEventID - id события строки лога: ее метка времени
+func logEventID(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// parseLogBufferSize - ?buffer= размер очереди отправки клиенту
+func parseLogBufferSize(c *gin.Context) int {
+	if n, err := strconv.Atoi(c.Query("buffer")); err == nil && n > 0 {
+		return n
+	}
+	return defaultLogBufferSize
+}
+
+// StartLogStreamHandler - Запуск лог-стрима через WebSocket или SSE
 func (h *Handler) StartLogStreamHandler(c *gin.Context) {
 	namespace := c.Param("namespace")
 	podName := c.Param("pod")
 
 	// Получаем параметры из запроса
-	tailLines := int64(100)
-	if tailStr := c.Query("tail"); tailStr != "" {
-		if n, err := fmt.Sscanf(tailStr, "%d", &tailLines); err != nil || n != 1 {
-			tailLines = 100
-		}
+	logReq, ok := parseLogRequest(c, 100, true)
+	if !ok {
+		return
+	}
+
+	tailLines := int64(-1)
+	if logReq.Options.TailLines != nil {
+		tailLines = *logReq.Options.TailLines
 	}
 
 	follow := c.Query("follow") == "true"
-	bufferSize := 100
-	if bufStr := c.Query("buffer"); bufStr != "" {
-		if n, err := fmt.Sscanf(bufStr, "%d", &bufferSize); err != nil || n != 1 {
-			bufferSize = 100
-		}
+
+	cluster := h.cluster(c)
+
+	// Создаем ID стрима
+	streamID := fmt.Sprintf("%s-%s-%s-%d", cluster.Name, namespace, podName, time.Now().UnixNano())
+
+	stream := newLogStream(streamID, parseLogBufferSize(c))
+	stream.Cluster = cluster.Name
+	stream.Namespace = namespace
+	stream.Pod = podName
+	stream.Container = logReq.Options.Container
+	stream.AllContainers = logReq.AllContainers
+	stream.Options = logReq.Options
+	stream.Filter = logReq.Filter
+	stream.TailLines = tailLines
+	stream.Follow = follow
+	if since, ok := logResumePoint(c); ok {
+		stream.ResumeFrom = since
 	}
 
-	// Обновляем соединение до WebSocket
-	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	// WebSocket или SSE (EventSource, curl)
+	sink, err := openEventSink(c)
 	if err != nil {
 		log.Printf("WebSocket upgrade error: %v", err)
 		return
 	}
-	defer ws.Close()
 
-	// Создаем ID стрима
-	streamID := fmt.Sprintf("%s-%s-%d", namespace, podName, time.Now().UnixNano())
-
-	stream := &LogStream{
-		ID:         streamID,
-		Namespace:  namespace,
-		Pod:        podName,
-		Conn:       ws,
-		StopChan:   make(chan struct{}),
-		BufferSize: bufferSize,
-		TailLines:  tailLines,
-		Follow:     follow,
-	}
+	manager := GetLogStreamManager()
+	manager.AddStream(stream)
+	done := stream.serve(sink)
 
-	// Сохраняем стрим
-	logStreamsMu.Lock()
-	logStreams[streamID] = stream
-	logStreamsMu.Unlock()
-
-	// Удаляем стрим при завершении
+	// Удаляем стрим при завершении, дождавшись отправки последних сообщений
 	defer func() {
-		logStreamsMu.Lock()
-		delete(logStreams, streamID)
-		logStreamsMu.Unlock()
-		close(stream.StopChan)
+		manager.RemoveStream(streamID)
+		stream.Stop()
+		<-done
 		log.Printf("Log stream stopped: %s/%s", namespace, podName)
 	}()
 
@@ -130,16 +149,16 @@ func (h *Handler) StartLogStreamHandler(c *gin.Context) {
 		namespace, podName, follow, tailLines)
 
 	// Отправляем начальное сообщение
-	ws.WriteJSON(LogMessage{
+	stream.Send(LogMessage{
 		Type:    "info",
 		Message: fmt.Sprintf("Log stream started for pod %s/%s", namespace, podName),
+		Data:    gin.H{"id": streamID},
 		Time:    time.Now().Format(time.RFC3339),
 	})
 
 	// Запускаем чтение логов
-	err = h.streamPodLogs(stream, h.clientset)
-	if err != nil {
-		ws.WriteJSON(LogMessage{
+	if err := h.streamPodLogs(stream, cluster.Clientset); err != nil {
+		stream.Send(LogMessage{
 			Type:    "error",
 			Message: fmt.Sprintf("Error streaming logs: %v", err),
 			Time:    time.Now().Format(time.RFC3339),
@@ -147,12 +166,12 @@ func (h *Handler) StartLogStreamHandler(c *gin.Context) {
 	}
 }
 
-// streamPodLogs - Чтение и отправка логов через WebSocket
+// streamPodLogs - Чтение логов пода и отправка в стрим
 func (h *Handler) streamPodLogs(stream *LogStream, clientset *kubernetes.Clientset) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Отслеживаем закрытие WebSocket
+	// Отслеживаем остановку стрима
 	go func() {
 		<-stream.StopChan
 		cancel()
@@ -161,251 +180,268 @@ func (h *Handler) streamPodLogs(stream *LogStream, clientset *kubernetes.Clients
 	// Получаем информацию о поде
 	pod, err := clientset.CoreV1().Pods(stream.Namespace).Get(ctx, stream.Pod, metav1.GetOptions{})
 	if err != nil {
-		stream.Conn.WriteJSON(LogMessage{
-			Type:    "error",
-			Message: fmt.Sprintf("Pod not found: %v", err),
-			Time:    time.Now().Format(time.RFC3339),
-		})
-		return err
+		return fmt.Errorf("pod not found: %w", err)
 	}
 
 	// Отправляем информацию о поде
-	stream.Conn.WriteJSON(LogMessage{
+	stream.Send(LogMessage{
 		Type: "info",
 		Message: fmt.Sprintf("Pod: %s, Status: %s, Node: %s",
 			pod.Name, pod.Status.Phase, pod.Spec.NodeName),
 		Time: time.Now().Format(time.RFC3339),
 	})
 
-	// Если в поде несколько контейнеров, нужно указать конкретный контейнер
-	// или получить логи из первого контейнера
-	containerName := ""
-	if len(pod.Spec.Containers) > 0 {
-		containerName = pod.Spec.Containers[0].Name
+	// Все контейнеры пода - отдельные потоки, слитые по времени
+	if stream.AllContainers {
+		return h.streamAllContainerLogs(ctx, stream, clientset, pod)
 	}
 
-	// Получаем логи с правильными параметрами
-	podLogOpts := &corev1.PodLogOptions{
-		Container:  containerName,
-		TailLines:  &stream.TailLines,
-		Follow:     stream.Follow,
-		Timestamps: true, // Добавляем временные метки
+	// Контейнер из запроса, иначе контейнер по умолчанию
+	containerName := stream.Container
+	if containerName == "" {
+		containerName = defaultContainer(pod)
 	}
 
 	log.Printf("Requesting logs for pod %s/%s, container: %s, follow: %v, tail: %d",
 		stream.Namespace, stream.Pod, containerName, stream.Follow, stream.TailLines)
 
-	req := clientset.CoreV1().Pods(stream.Namespace).GetLogs(stream.Pod, podLogOpts)
-
-	podLogs, err := req.Stream(ctx)
-	if err != nil {
-		errorMsg := fmt.Sprintf("Failed to get log stream: %v", err)
-		log.Printf(errorMsg)
-
-		// Попробуем получить логи без указания контейнера
-		if containerName != "" {
-			log.Printf("Trying without container name...")
-			podLogOpts.Container = ""
-			req = clientset.CoreV1().Pods(stream.Namespace).GetLogs(stream.Pod, podLogOpts)
-			podLogs, err = req.Stream(ctx)
-
-			if err != nil {
-				stream.Conn.WriteJSON(LogMessage{
-					Type:    "error",
-					Message: errorMsg,
-					Time:    time.Now().Format(time.RFC3339),
-				})
-				return err
-			}
-		} else {
-			stream.Conn.WriteJSON(LogMessage{
-				Type:    "error",
-				Message: errorMsg,
-				Time:    time.Now().Format(time.RFC3339),
-			})
-			return err
+	err = followContainerLogs(ctx, stream, clientset, containerName, func(line logLine) {
+		// Фильтруем на сервере, чтобы не гонять лишнее в браузер
+		level, fields := analyzeLogLine(line.Text)
+		if !stream.Filter.Match(line.Text, level) {
+			return
 		}
+
+		stream.SendLog(LogMessage{
+			ID:      logEventID(line.Time),
+			Type:    "log",
+			Message: formatLogLine(line, stream.Options.Timestamps, false),
+			Level:   level,
+			Fields:  fields,
+			Data:    gin.H{"container": containerName},
+			Time:    time.Now().Format(time.RFC3339),
+		})
+	})
+	if err != nil || ctx.Err() != nil {
+		return err
 	}
-	defer podLogs.Close()
 
-	// Сообщаем об успешном подключении
-	stream.Conn.WriteJSON(LogMessage{
+	stream.Send(LogMessage{
 		Type:    "info",
-		Message: "Successfully connected to pod logs",
+		Message: "Log stream completed",
 		Time:    time.Now().Format(time.RFC3339),
 	})
+	return nil
+}
 
-	// Используем Scanner для построчного чтения
-	scanner := bufio.NewScanner(podLogs)
+// followContainerLogs - чтение лога контейнера. В режиме follow после обрыва (рестарт
+// контейнера, таймаут kubelet) поток открывается заново с метки времени последней строки,
+// паузы между попытками растут от logReconnectMin до logReconnectMax.
+// Завершается при остановке стрима, удалении пода, его завершении или завершении контейнера без перезапуска.
+func followContainerLogs(ctx context.Context, stream *LogStream, clientset *kubernetes.Clientset, container string, emit func(logLine)) error {
+	backoff := logReconnectMin
+	warned := false
+	last := stream.ResumeFrom // после переподключения клиента продолжаем с его последней строки
 
-	// Увеличиваем максимальный размер буфера (логи могут быть длинными)
-	const maxCapacity = 1024 * 1024 // 1MB
-	buf := make([]byte, maxCapacity)
-	scanner.Buffer(buf, maxCapacity)
+	for {
+		opts := stream.Options
+		opts.Container = container
+		opts.Follow = stream.Follow
+		opts.Timestamps = true // метка последней строки нужна для продолжения
+		if !last.IsZero() {
+			opts.TailLines = nil
+			opts.SinceSeconds = nil
+			since := metav1.NewTime(last)
+			opts.SinceTime = &since
+		}
 
-	for scanner.Scan() {
-		select {
-		case <-stream.StopChan:
-			stream.Conn.WriteJSON(LogMessage{
-				Type:    "info",
-				Message: "Log stream stopped by user",
-				Time:    time.Now().Format(time.RFC3339),
-			})
-			return nil
+		resumeAfter := last
+		received := 0
+
+		podLogs, err := clientset.CoreV1().Pods(stream.Namespace).GetLogs(stream.Pod, &opts).Stream(ctx)
+		if err == nil {
+			scanner := bufio.NewScanner(podLogs)
+			const maxCapacity = 1024 * 1024 // 1MB
+			scanner.Buffer(make([]byte, 64*1024), maxCapacity)
+
+			for scanner.Scan() {
+				t, text, ok := k8s.SplitLogTimestamp(scanner.Text())
+				if ok {
+					// sinceTime округляется до секунды - пропускаем уже отправленное
+					if !resumeAfter.IsZero() && !t.After(resumeAfter) {
+						continue
+					}
+					last = t
+				} else {
+					t = last
+				}
+
+				received++
+				emit(logLine{Time: t, Container: container, Text: text})
+			}
+			err = scanner.Err()
+			podLogs.Close()
+		}
 
-		case <-ctx.Done():
+		if ctx.Err() != nil {
 			return nil
+		}
+		if !stream.Follow {
+			return err
+		}
 
-		default:
-			line := scanner.Text()
+		// Под удален или завершился - новых строк не будет
+		pod, getErr := clientset.CoreV1().Pods(stream.Namespace).Get(ctx, stream.Pod, metav1.GetOptions{})
+		if apierrors.IsNotFound(getErr) {
+			return fmt.Errorf("pod %s/%s was deleted", stream.Namespace, stream.Pod)
+		}
+		if getErr == nil && (pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed) {
+			return nil
+		}
+		// Контейнер завершился без перезапуска (init-контейнер, restartPolicy Never/OnFailure)
+		if getErr == nil && k8s.ContainerFinished(pod, container) {
+			return nil
+		}
 
-			// Отправляем строку лога
-			err := stream.Conn.WriteJSON(LogMessage{
-				Type:    "log",
-				Message: line,
-				Time:    time.Now().Format(time.RFC3339),
-			})
+		if received > 0 {
+			backoff = logReconnectMin
+			warned = false
+		}
 
+		// Предупреждаем один раз за обрыв, а не на каждую попытку
+		if !warned && (err != nil || received > 0) {
+			warned = true
+			reason := "stream ended (container restarted?)"
 			if err != nil {
-				log.Printf("WebSocket write error: %v", err)
-				return err
+				reason = err.Error()
+			}
+			msg := LogMessage{
+				Type:    "warning",
+				Message: fmt.Sprintf("Logs of %s interrupted: %s. Reconnecting...", container, reason),
+				Data:    gin.H{"container": container},
+				Time:    time.Now().Format(time.RFC3339),
+			}
+			if !last.IsZero() {
+				msg.Data = gin.H{"container": container, "resumeFrom": last.Format(time.RFC3339Nano)}
 			}
+			stream.Send(msg)
 		}
-	}
 
-	// Проверяем ошибку сканера
-	if err := scanner.Err(); err != nil {
-		if err == context.Canceled {
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
 			return nil
 		}
-
-		log.Printf("Scanner error: %v", err)
-		stream.Conn.WriteJSON(LogMessage{
-			Type:    "error",
-			Message: fmt.Sprintf("Error reading logs: %v", err),
-			Time:    time.Now().Format(time.RFC3339),
-		})
-		return err
-	}
-
-	// Если мы здесь, значит сканер закончил (EOF)
-	if stream.Follow {
-		// В режиме follow это может означать, что под перезапустился
-		stream.Conn.WriteJSON(LogMessage{
-			Type:    "warning",
-			Message: "Pod logs ended (pod might have restarted). Trying to reconnect...",
-			Time:    time.Now().Format(time.RFC3339),
-		})
-
-		// Ждем и пытаемся переподключиться
-		time.Sleep(2 * time.Second)
-		return h.streamPodLogs(stream, clientset) // Рекурсивная переподключка
+		backoff = min(backoff*2, logReconnectMax)
 	}
-
-	// Если не follow, просто завершаем
-	stream.Conn.WriteJSON(LogMessage{
-		Type:    "info",
-		Message: "Log stream completed",
-		Time:    time.Now().Format(time.RFC3339),
-	})
-
-	return nil
 }
 
-// StopLogStreamHandler - Остановка лог-стрима
-func (h *Handler) StopLogStreamHandler(c *gin.Context) {
-	streamID := c.Param("id")
-
-	logStreamsMu.Lock()
-	stream, exists := logStreams[streamID]
-	logStreamsMu.Unlock()
-
-	if !exists {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Log stream not found"})
-		return
+// logReorderWindow - окно, в пределах которого строки разных контейнеров упорядочиваются по времени
+const logReorderWindow = 250 * time.Millisecond
+
+// streamAllContainerLogs - логи всех контейнеров пода в одном стриме, строки помечены контейнером
+func (h *Handler) streamAllContainerLogs(ctx context.Context, stream *LogStream, clientset *kubernetes.Clientset, pod *corev1.Pod) error {
+	lines := make(chan logLine, stream.BufferSize)
+	containers := k8s.LogContainers(pod)
+
+	var wg sync.WaitGroup
+	for _, container := range containers {
+		wg.Add(1)
+		go func(container string) {
+			defer wg.Done()
+
+			err := followContainerLogs(ctx, stream, clientset, container, func(line logLine) {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+				}
+			})
+			if err != nil {
+				// Например, init-контейнер еще не запускался
+				stream.Send(LogMessage{
+					Type:    "warning",
+					Message: fmt.Sprintf("Container %s: %v", container, err),
+					Data:    gin.H{"container": container},
+					Time:    time.Now().Format(time.RFC3339),
+				})
+			}
+		}(container)
 	}
 
-	// Закрываем канал остановки
-	close(stream.StopChan)
-
-	// Удаляем из хранилища
-	logStreamsMu.Lock()
-	delete(logStreams, streamID)
-	logStreamsMu.Unlock()
-
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Log stream stopped",
-		"stream":  streamID,
-	})
-}
-
-// WatchPodsHandler - WebSocket для отслеживания изменений подов в реальном времени
-func (h *Handler) WatchPodsHandler(c *gin.Context) {
-	namespace := c.DefaultQuery("namespace", "default")
-
-	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
-	if err != nil {
-		log.Printf("WebSocket upgrade error: %v", err)
-		return
-	}
-	defer ws.Close()
+	go func() {
+		wg.Wait()
+		close(lines)
+	}()
 
-	// Начальное сообщение
-	ws.WriteJSON(LogMessage{
+	stream.Send(LogMessage{
 		Type:    "info",
-		Message: fmt.Sprintf("Started watching pods in namespace: %s", namespace),
+		Message: fmt.Sprintf("Streaming logs of %d containers", len(containers)),
 		Time:    time.Now().Format(time.RFC3339),
 	})
 
-	// Канал для остановки
-	stopChan := make(chan struct{})
-	defer close(stopChan)
+	ticker := time.NewTicker(logReorderWindow)
+	defer ticker.Stop()
 
-	// Создаем watcher для подов
-	watcher, err := h.clientset.CoreV1().Pods(namespace).Watch(c.Request.Context(), metav1.ListOptions{})
-	if err != nil {
-		ws.WriteJSON(LogMessage{
-			Type:    "error",
-			Message: fmt.Sprintf("Failed to create pod watcher: %v", err),
-			Time:    time.Now().Format(time.RFC3339),
+	var pending []logLine
+	flush := func() {
+		sort.SliceStable(pending, func(i, j int) bool {
+			return pending[i].Time.Before(pending[j].Time)
 		})
-		return
+		for _, line := range pending {
+			level, fields := analyzeLogLine(line.Text)
+			if !stream.Filter.Match(line.Text, level) {
+				continue
+			}
+
+			stream.SendLog(LogMessage{
+				ID:      logEventID(line.Time),
+				Type:    "log",
+				Message: formatLogLine(line, stream.Options.Timestamps, true),
+				Level:   level,
+				Fields:  fields,
+				Data:    gin.H{"container": line.Container},
+				Time:    time.Now().Format(time.RFC3339),
+			})
+		}
+		pending = pending[:0]
 	}
-	defer watcher.Stop()
 
-	// Читаем события
 	for {
 		select {
-		case <-stopChan:
-			return
+		case <-ctx.Done():
+			return nil
 
-		case event, ok := <-watcher.ResultChan():
+		case line, ok := <-lines:
 			if !ok {
-				ws.WriteJSON(LogMessage{
-					Type:    "warning",
-					Message: "Pod watch channel closed",
+				flush()
+				stream.Send(LogMessage{
+					Type:    "info",
+					Message: "Log stream completed",
 					Time:    time.Now().Format(time.RFC3339),
 				})
-				return
+				return nil
 			}
+			pending = append(pending, line)
 
-			pod, ok := event.Object.(*corev1.Pod)
-			if !ok {
-				continue
+		case <-ticker.C:
+			if len(pending) > 0 {
+				flush()
 			}
-
-			// Отправляем событие
-			ws.WriteJSON(map[string]interface{}{
-				"type":      string(event.Type),
-				"pod":       pod.Name,
-				"namespace": pod.Namespace,
-				"status":    string(pod.Status.Phase),
-				"time":      time.Now().Format(time.RFC3339),
-				"event": map[string]interface{}{
-					"type":   event.Type,
-					"object": pod,
-				},
-			})
 		}
 	}
 }
+
+// StopLogStreamHandler - Остановка лог-стрима
+func (h *Handler) StopLogStreamHandler(c *gin.Context) {
+	streamID := c.Param("id")
+
+	if !GetLogStreamManager().StopStream(streamID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Log stream not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Log stream stopped",
+		"stream":  streamID,
+	})
+}
@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Параметры доставки лог-стримов
+const (
+	defaultLogBufferSize = 1000             // строк в очереди на отправку клиенту
+	logControlBufferSize = 100              // служебных сообщений в очереди на отправку клиенту
+	logWriteWait         = 10 * time.Second // таймаут записи одного сообщения
+	logPingInterval      = 30 * time.Second // keepalive-пинг клиенту
+	logPongWait          = 60 * time.Second // клиент считается отключенным без pong
+	logReconnectMin      = 1 * time.Second  // пауза перед первым переподключением к kubelet
+	logReconnectMax      = 30 * time.Second // потолок паузы между переподключениями
+)
+
+type LogStream struct {
+	ID            string
+	Cluster       string
+	Namespace     string
+	Pod           string
+	Selector      string
+	Container     string
+	AllContainers bool
+	Options       corev1.PodLogOptions
+	Filter        *LogFilter
+	StopChan      chan struct{}
+	BufferSize    int
+	TailLines     int64
+	Follow        bool
+	ResumeFrom    time.Time // Last-Event-ID: строки не новее уже получены клиентом
+	CreatedAt     time.Time
+
+	stopOnce sync.Once
+	out      *logQueue
+}
+
+// newLogStream - стрим с очередью отправки на bufferSize строк
+func newLogStream(id string, bufferSize int) *LogStream {
+	if bufferSize <= 0 {
+		bufferSize = defaultLogBufferSize
+	}
+	return &LogStream{
+		ID:         id,
+		StopChan:   make(chan struct{}),
+		BufferSize: bufferSize,
+		CreatedAt:  time.Now(),
+		out:        newLogQueue(bufferSize),
+	}
+}
+
+// Stop - останавливает стрим; повторный вызов безопасен
+func (s *LogStream) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.StopChan)
+	})
+}
+
+// Send - служебное сообщение (info, warning, error); вытесняется только при переполнении
+// отдельной очереди служебных сообщений
+func (s *LogStream) Send(msg LogMessage) {
+	s.out.push(msg, false)
+}
+
+// SendLog - строка лога; при медленном клиенте старые строки вытесняются
+func (s *LogStream) SendLog(msg LogMessage) {
+	s.out.push(msg, true)
+}
+
+// Dropped - сколько строк отброшено из-за медленного клиента
+func (s *LogStream) Dropped() int64 {
+	return s.out.droppedTotal()
+}
+
+// deliver - единственный писатель клиенту: отправляет очередь, шлет keepalive и
+// после остановки дописывает оставшееся. Ошибка записи останавливает стрим.
+func (s *LogStream) deliver(sink eventSink) {
+	defer s.Stop()
+
+	ticker := time.NewTicker(sink.PingInterval())
+	defer ticker.Stop()
+
+	for {
+		stopped := false
+		select {
+		case <-s.out.ready:
+		case <-ticker.C:
+			if err := sink.Ping(); err != nil {
+				return
+			}
+			continue
+		case <-s.StopChan:
+			stopped = true
+		}
+
+		batch, dropped, droppedControl := s.out.take()
+		if dropped > 0 || droppedControl > 0 {
+			// Вместо потерянных сообщений - одно сообщение с их количеством
+			message := fmt.Sprintf("%d log lines dropped: client is too slow", dropped)
+			if droppedControl > 0 {
+				message = fmt.Sprintf("%d log lines and %d status messages dropped: client is too slow", dropped, droppedControl)
+			}
+			if err := sink.Send("", LogMessage{
+				Type:    "warning",
+				Message: message,
+				Data:    map[string]interface{}{"dropped": dropped, "droppedMessages": droppedControl},
+				Time:    time.Now().Format(time.RFC3339),
+			}); err != nil {
+				return
+			}
+		}
+		for _, msg := range batch {
+			if err := sink.Send(msg.ID, msg); err != nil {
+				log.Printf("Log stream %s write error: %v", s.ID, err)
+				return
+			}
+		}
+
+		if stopped {
+			return
+		}
+	}
+}
+
+// serve - доставка стрима клиенту (WebSocket или SSE); возвращает канал,
+// закрываемый после отправки последнего сообщения и закрытия соединения
+func (s *LogStream) serve(sink eventSink) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-sink.Closed():
+			s.Stop()
+		case <-s.StopChan:
+		}
+	}()
+
+	go func() {
+		defer close(done)
+		defer sink.Close()
+		s.deliver(sink)
+	}()
+
+	return done
+}
+
+// logQueue - ограниченная очередь отправки: строки лога и служебные сообщения лежат в двух
+// кольцевых буферах фиксированного размера, при переполнении вытесняются самые старые записи.
+// Служебных сообщений мало, поэтому их буфер меньше и переполняется только при зависшем клиенте.
+type logQueue struct {
+	mu             sync.Mutex
+	lines          logRing
+	control        logRing
+	seq            uint64
+	dropped        int64 // строк с последнего take
+	droppedControl int64 // служебных сообщений с последнего take
+	total          int64
+	ready          chan struct{}
+}
+
+type logQueueItem struct {
+	seq uint64 // порядок добавления для слияния буферов
+	msg LogMessage
+}
+
+// logRing - кольцевой буфер фиксированной емкости
+type logRing struct {
+	items []logQueueItem
+	head  int // индекс самой старой записи
+	count int
+}
+
+func newLogRing(size int) logRing {
+	return logRing{items: make([]logQueueItem, size)}
+}
+
+// push - добавляет запись; при заполненном буфере затирает самую старую и возвращает true
+func (r *logRing) push(item logQueueItem) bool {
+	if r.count == len(r.items) {
+		r.items[r.head] = item
+		r.head = (r.head + 1) % len(r.items)
+		return true
+	}
+	r.items[(r.head+r.count)%len(r.items)] = item
+	r.count++
+	return false
+}
+
+// at - i-я по старшинству запись
+func (r *logRing) at(i int) logQueueItem {
+	return r.items[(r.head+i)%len(r.items)]
+}
+
+// reset - очищает буфер, отпуская сообщения для GC
+func (r *logRing) reset() {
+	clear(r.items)
+	r.head = 0
+	r.count = 0
+}
+
+func newLogQueue(limit int) *logQueue {
+	return &logQueue{
+		lines:   newLogRing(limit),
+		control: newLogRing(logControlBufferSize),
+		ready:   make(chan struct{}, 1),
+	}
+}
+
+func (q *logQueue) push(msg LogMessage, droppable bool) {
+	q.mu.Lock()
+	q.seq++
+	item := logQueueItem{seq: q.seq, msg: msg}
+	if droppable {
+		if q.lines.push(item) {
+			q.dropped++
+			q.total++
+		}
+	} else if q.control.push(item) {
+		q.droppedControl++
+	}
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// take - забирает все накопленное в порядке добавления и число вытесненных строк и служебных сообщений
+func (q *logQueue) take() ([]LogMessage, int64, int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	batch := make([]LogMessage, 0, q.lines.count+q.control.count)
+	i, j := 0, 0
+	for i < q.lines.count || j < q.control.count {
+		if j == q.control.count || (i < q.lines.count && q.lines.at(i).seq < q.control.at(j).seq) {
+			batch = append(batch, q.lines.at(i).msg)
+			i++
+		} else {
+			batch = append(batch, q.control.at(j).msg)
+			j++
+		}
+	}
+	q.lines.reset()
+	q.control.reset()
+
+	dropped, droppedControl := q.dropped, q.droppedControl
+	q.dropped, q.droppedControl = 0, 0
+	return batch, dropped, droppedControl
+}
+
+func (q *logQueue) droppedTotal() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.total
+}
+
+// LogStreamManager - активные лог-стримы (WebSocket и SSE)
+type LogStreamManager struct {
+	streams map[string]*LogStream
+	mu      sync.RWMutex
+}
+
+var logStreamManager = &LogStreamManager{
+	streams: make(map[string]*LogStream),
+}
+
+func GetLogStreamManager() *LogStreamManager {
+	return logStreamManager
+}
+
+func (m *LogStreamManager) GetStreams() []*LogStream {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	streams := make([]*LogStream, 0, len(m.streams))
+	for _, stream := range m.streams {
+		streams = append(streams, stream)
+	}
+	return streams
+}
+
+func (m *LogStreamManager) AddStream(stream *LogStream) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.streams[stream.ID] = stream
+}
+
+func (m *LogStreamManager) RemoveStream(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.streams, id)
+}
+
+func (m *LogStreamManager) GetStream(id string) (*LogStream, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stream, exists := m.streams[id]
+	return stream, exists
+}
+
+// StopStream - останавливает стрим; из списка он удаляется своим обработчиком
+func (m *LogStreamManager) StopStream(id string) bool {
+	stream, exists := m.GetStream(id)
+	if !exists {
+		return false
+	}
+
+	stream.Send(LogMessage{
+		Type:    "info",
+		Message: "Log stream stopped by user",
+		Time:    time.Now().Format(time.RFC3339),
+	})
+	stream.Stop()
+	return true
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestLogQueue(t *testing.T) {
+	q := newLogQueue(3)
+	push := func(message string, droppable bool) {
+		q.push(LogMessage{Message: message}, droppable)
+	}
+	messages := func(batch []LogMessage) []string {
+		result := []string{}
+		for _, msg := range batch {
+			result = append(result, msg.Message)
+		}
+		return result
+	}
+
+	push("info", false)
+	for i := 1; i <= 5; i++ {
+		push(fmt.Sprintf("line %d", i), true)
+	}
+	push("warning", false)
+
+	batch, dropped, droppedControl := q.take()
+	want := []string{"info", "line 3", "line 4", "line 5", "warning"}
+	if got := messages(batch); !reflect.DeepEqual(got, want) {
+		t.Errorf("batch = %v, want %v", got, want)
+	}
+	if dropped != 2 || droppedControl != 0 {
+		t.Errorf("dropped = %d/%d, want 2/0", dropped, droppedControl)
+	}
+
+	for i := 0; i < logControlBufferSize+5; i++ {
+		push(fmt.Sprintf("status %d", i), false)
+	}
+	push("line 6", true)
+
+	batch, dropped, droppedControl = q.take()
+	if len(batch) != logControlBufferSize+1 {
+		t.Fatalf("len(batch) = %d, want %d", len(batch), logControlBufferSize+1)
+	}
+	if batch[0].Message != "status 5" || batch[len(batch)-1].Message != "line 6" {
+		t.Errorf("batch = %v ... %v", batch[0].Message, batch[len(batch)-1].Message)
+	}
+	if dropped != 0 || droppedControl != 5 {
+		t.Errorf("dropped = %d/%d, want 0/5", dropped, droppedControl)
+	}
+
+	if batch, _, _ := q.take(); len(batch) != 0 {
+		t.Errorf("queue not empty after take: %v", messages(batch))
+	}
+	if total := q.droppedTotal(); total != 2 {
+		t.Errorf("droppedTotal = %d, want 2", total)
+	}
+}
@@ -14,17 +14,19 @@ import (
 func (h *Handler) GetPodMetricsHandler(c *gin.Context) {
 	namespace := c.Param("namespace")
 
-	if h.clientset == nil || h.metricsClient == nil {
+	clientset := h.clientset(c)
+	metricsClient := h.metricsClient(c)
+	if clientset == nil || metricsClient == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{
 			"error":          "K8s or Metrics client not ready",
-			"clientset":      h.clientset != nil,
-			"metrics_client": h.metricsClient != nil,
+			"clientset":      clientset != nil,
+			"metrics_client": metricsClient != nil,
 		})
 		return
 	}
 
 	// Получаем метрики подов
-	metrics, err := k8s.GetPodMetrics(h.metricsClient, h.clientset, namespace)
+	metrics, err := k8s.GetPodMetrics(metricsClient, h.resources(c), namespace, liveRead(c))
 	if err != nil {
 		// Если метрики недоступны, возвращаем заглушку
 		c.JSON(http.StatusOK, gin.H{
@@ -65,13 +67,15 @@ func (h *Handler) GetSinglePodMetricsHandler(c *gin.Context) {
 	namespace := c.Param("namespace")
 	podName := c.Param("pod")
 
-	if h.clientset == nil || h.metricsClient == nil {
+	clientset := h.clientset(c)
+	metricsClient := h.metricsClient(c)
+	if clientset == nil || metricsClient == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s or Metrics client not ready"})
 		return
 	}
 
 	// Получаем метрики пода
-	podMetrics, pod, err := k8s.GetSinglePodMetrics(h.metricsClient, h.clientset, namespace, podName)
+	podMetrics, pod, err := k8s.GetSinglePodMetrics(metricsClient, h.resources(c), namespace, podName, liveRead(c))
 	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
 			"pod":       podName,
@@ -162,13 +166,15 @@ func (h *Handler) GetSinglePodMetricsHandler(c *gin.Context) {
 }
 
 func (h *Handler) GetAllPodsMetricsHandler(c *gin.Context) {
-	if h.clientset == nil || h.metricsClient == nil {
+	clientset := h.clientset(c)
+	metricsClient := h.metricsClient(c)
+	if clientset == nil || metricsClient == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s or Metrics client not ready"})
 		return
 	}
 
 	// Получаем все метрики подов
-	allMetrics, totalCPU, totalMemory, err := k8s.GetAllPodsMetrics(h.metricsClient, h.clientset)
+	allMetrics, totalCPU, totalMemory, err := k8s.GetAllPodsMetrics(metricsClient, h.resources(c), liveRead(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -190,13 +196,15 @@ func (h *Handler) GetAllPodsMetricsHandler(c *gin.Context) {
 }
 
 func (h *Handler) GetNodeMetricsHandler(c *gin.Context) {
-	if h.clientset == nil || h.metricsClient == nil {
+	clientset := h.clientset(c)
+	metricsClient := h.metricsClient(c)
+	if clientset == nil || metricsClient == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s or Metrics client not ready"})
 		return
 	}
 
 	// Получаем метрики нод
-	nodeMetrics, clusterMetrics, err := k8s.GetNodeMetrics(h.metricsClient, h.clientset)
+	nodeMetrics, clusterMetrics, err := k8s.GetNodeMetrics(metricsClient, h.resources(c), liveRead(c))
 	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
 			"error": "Node metrics not available: " + err.Error(),
@@ -239,7 +247,8 @@ func (h *Handler) GetNodeMetricsHandler(c *gin.Context) {
 
 // CheckMetricsHandler - проверка доступности метрик
 func (h *Handler) CheckMetricsHandler(c *gin.Context) {
-	if h.metricsClient == nil {
+	metricsClient := h.metricsClient(c)
+	if metricsClient == nil {
 		c.JSON(http.StatusOK, gin.H{
 			"metrics_available": false,
 			"error":             "Metrics client not initialized",
@@ -248,7 +257,7 @@ func (h *Handler) CheckMetricsHandler(c *gin.Context) {
 	}
 
 	// Пробуем получить метрики нод
-	_, err := h.metricsClient.MetricsV1beta1().NodeMetricses().List(c.Request.Context(), metav1.ListOptions{})
+	_, err := metricsClient.MetricsV1beta1().NodeMetricses().List(c.Request.Context(), metav1.ListOptions{})
 
 	c.JSON(http.StatusOK, gin.H{
 		"metrics_available": err == nil,
@@ -264,7 +273,9 @@ func (h *Handler) CheckMetricsHandler(c *gin.Context) {
 
 // GetPodMetricsSummaryHandler - краткая сводка по метрикам
 func (h *Handler) GetPodMetricsSummaryHandler(c *gin.Context) {
-	if h.clientset == nil || h.metricsClient == nil {
+	clientset := h.clientset(c)
+	metricsClient := h.metricsClient(c)
+	if clientset == nil || metricsClient == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s or Metrics client not ready"})
 		return
 	}
@@ -272,7 +283,7 @@ func (h *Handler) GetPodMetricsSummaryHandler(c *gin.Context) {
 	namespace := c.DefaultQuery("namespace", "default")
 
 	// Получаем метрики подов
-	metrics, err := k8s.GetPodMetrics(h.metricsClient, h.clientset, namespace)
+	metrics, err := k8s.GetPodMetrics(metricsClient, h.resources(c), namespace, liveRead(c))
 	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
 			"namespace": namespace,
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maxOwnerDepth - защита от циклов в ownerReferences
+const maxOwnerDepth = 10
+
+// GetPodOwnersHandler - цепочка владельцев пода до контроллера верхнего уровня
+func (h *Handler) GetPodOwnersHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	podName := c.Param("pod")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	pod, err := clientset.CoreV1().Pods(namespace).Get(c.Request.Context(), podName, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	chain := resolveOwnerChain(c.Request.Context(), clientset, pod)
+
+	c.JSON(http.StatusOK, gin.H{
+		"pod":       podName,
+		"namespace": namespace,
+		"chain":     chain,
+		"top":       chain[len(chain)-1],
+	})
+}
+
+// controllerRef - ссылка на контроллер (controller=true), иначе первая ссылка
+func controllerRef(refs []metav1.OwnerReference) *metav1.OwnerReference {
+	for i := range refs {
+		if refs[i].Controller != nil && *refs[i].Controller {
+			return &refs[i]
+		}
+	}
+	if len(refs) > 0 {
+		return &refs[0]
+	}
+	return nil
+}
+
+// resolveOwnerChain - поднимается по ownerReferences: Pod → ReplicaSet → Deployment.
+// Первый элемент - сам под, последний - контроллер верхнего уровня.
+func resolveOwnerChain(ctx context.Context, clientset *kubernetes.Clientset, pod *corev1.Pod) []gin.H {
+	chain := []gin.H{{
+		"kind":      "Pod",
+		"name":      pod.Name,
+		"namespace": pod.Namespace,
+		"uid":       string(pod.UID),
+		"resolved":  true,
+	}}
+
+	namespace := pod.Namespace
+	ref := controllerRef(pod.OwnerReferences)
+
+	for depth := 0; ref != nil && depth < maxOwnerDepth; depth++ {
+		link := gin.H{
+			"kind":       ref.Kind,
+			"name":       ref.Name,
+			"namespace":  namespace,
+			"apiVersion": ref.APIVersion,
+			"uid":        string(ref.UID),
+			"resolved":   false,
+		}
+
+		// Node владеет static-подами и не имеет namespace
+		if ref.Kind == "Node" {
+			link["namespace"] = ""
+			chain = append(chain, link)
+			break
+		}
+
+		owners, known, err := getOwnerReferences(ctx, clientset, namespace, ref)
+		if err != nil {
+			link["error"] = err.Error()
+			chain = append(chain, link)
+			break
+		}
+
+		link["resolved"] = known
+		chain = append(chain, link)
+
+		if !known {
+			break
+		}
+		ref = controllerRef(owners)
+	}
+
+	return chain
+}
+
+// getOwnerReferences - ownerReferences объекта по ссылке; known=false для неизвестных типов
+func getOwnerReferences(ctx context.Context, clientset *kubernetes.Clientset, namespace string, ref *metav1.OwnerReference) ([]metav1.OwnerReference, bool, error) {
+	var meta *metav1.ObjectMeta
+
+	switch ref.Kind {
+	case "ReplicaSet":
+		obj, err := clientset.AppsV1().ReplicaSets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, false, err
+		}
+		meta = &obj.ObjectMeta
+	case "Deployment":
+		obj, err := clientset.AppsV1().Deployments(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, false, err
+		}
+		meta = &obj.ObjectMeta
+	case "StatefulSet":
+		obj, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, false, err
+		}
+		meta = &obj.ObjectMeta
+	case "DaemonSet":
+		obj, err := clientset.AppsV1().DaemonSets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, false, err
+		}
+		meta = &obj.ObjectMeta
+	case "Job":
+		obj, err := clientset.BatchV1().Jobs(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, false, err
+		}
+		meta = &obj.ObjectMeta
+	case "CronJob":
+		obj, err := clientset.BatchV1().CronJobs(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, false, err
+		}
+		meta = &obj.ObjectMeta
+	case "ReplicationController":
+		obj, err := clientset.CoreV1().ReplicationControllers(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, false, err
+		}
+		meta = &obj.ObjectMeta
+	default:
+		// CRD-контроллеры (например, Strimzi) - дальше не поднимаемся
+		return nil, false, nil
+	}
+
+	return meta.OwnerReferences, true, nil
+}
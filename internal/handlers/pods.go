@@ -1,21 +1,31 @@
 package handlers
 
 import (
+	"archive/tar"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
-	"strconv"
+	"path"
+	"strings"
 	"time"
 
+	"k8s-manager/internal/k8s"
+
 	"github.com/gin-gonic/gin"
-	"gopkg.in/yaml.v3"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	sigsyaml "sigs.k8s.io/yaml"
 )
 
 func (h *Handler) GetPodsHandler(c *gin.Context) {
 	namespace := c.DefaultQuery("namespace", "market")
 
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{
 			"error": "Kubernetes client not initialized",
 			"tip":   "Check if ~/.kube/config exists and is accessible",
@@ -23,7 +33,7 @@ func (h *Handler) GetPodsHandler(c *gin.Context) {
 		return
 	}
 
-	pods, err := h.clientset.CoreV1().Pods(namespace).List(c.Request.Context(), metav1.ListOptions{})
+	pods, err := h.resources(c).ListPods(c.Request.Context(), namespace, liveRead(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -55,31 +65,28 @@ func (h *Handler) GetPodsHandler(c *gin.Context) {
 		"namespace": namespace,
 		"count":     len(pods.Items),
 		"pods":      result,
+		"source":    readSource(c),
 	})
 }
 
 func (h *Handler) GetLogsHandler(c *gin.Context) {
 	namespace := c.Param("namespace")
 	podName := c.Param("pod")
-	tailLines := c.DefaultQuery("tail", "100")
 
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{
 			"error": "Kubernetes client not initialized",
 		})
 		return
 	}
 
-	tail, err := strconv.ParseInt(tailLines, 10, 64)
-	if err != nil {
-		tail = 100
+	req, ok := parseLogRequest(c, 100, false)
+	if !ok {
+		return
 	}
 
-	req := h.clientset.CoreV1().Pods(namespace).GetLogs(podName, &corev1.PodLogOptions{
-		TailLines: &tail,
-	})
-
-	logs, err := req.Do(c.Request.Context()).Raw()
+	result, err := fetchPodLogs(c.Request.Context(), clientset, namespace, podName, req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
@@ -88,135 +95,273 @@ func (h *Handler) GetLogsHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"pod":        podName,
-		"namespace":  namespace,
-		"logs":       string(logs),
-		"tail_lines": tail,
-	})
+	result["pod"] = podName
+	result["namespace"] = namespace
+	result["tail_lines"] = req.Options.TailLines
+	result["previous"] = req.Options.Previous
+
+	c.JSON(http.StatusOK, result)
 }
 
 func (h *Handler) DownloadLogsHandler(c *gin.Context) {
 	namespace := c.Param("namespace")
 	podName := c.Param("pod")
-	tailLines := c.DefaultQuery("tail", "1000")
 
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
 		return
 	}
 
-	tail, err := strconv.ParseInt(tailLines, 10, 64)
-	if err != nil {
-		tail = 1000
+	req, ok := parseLogRequest(c, 1000, false)
+	if !ok {
+		return
 	}
 
-	req := h.clientset.CoreV1().Pods(namespace).GetLogs(podName, &corev1.PodLogOptions{
-		TailLines: &tail,
-	})
-
-	logs, err := req.Do(c.Request.Context()).Raw()
+	result, err := fetchPodLogs(c.Request.Context(), clientset, namespace, podName, req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	// В имени файла - контейнер (all - для всех контейнеров)
+	container := result["container"].(string)
+	if req.AllContainers {
+		container = "all"
+	}
+
 	// Устанавливаем заголовки для скачивания
 	c.Header("Content-Type", "text/plain")
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s.log", podName, time.Now().Format("20060102-150405")))
-	c.String(http.StatusOK, string(logs))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s-%s.log", podName, container, time.Now().Format("20060102-150405")))
+	c.String(http.StatusOK, result["logs"].(string))
 }
 
-func (h *Handler) GetPodYAMLHandler(c *gin.Context) {
+// DownloadPodFileHandler - скачивание файла или каталога из контейнера (tar через exec, как kubectl cp).
+// ?path=/tmp/heap.hprof&container=&format=tar|zip
+func (h *Handler) DownloadPodFileHandler(c *gin.Context) {
 	namespace := c.Param("namespace")
 	podName := c.Param("pod")
+	container := c.Query("container")
+	format := c.DefaultQuery("format", "tar")
 
-	if h.clientset == nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+	srcPath, ok := containerPath(c.Query("path"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "path must be an absolute path inside the container"})
+		return
+	}
+	if format != "tar" && format != "zip" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be tar or zip"})
 		return
 	}
 
-	pod, err := h.clientset.CoreV1().Pods(namespace).Get(c.Request.Context(), podName, metav1.GetOptions{})
+	container, ok = h.resolveContainer(c, namespace, podName, container)
+	if !ok {
+		return
+	}
+
+	cluster := h.cluster(c)
+	dir, name := path.Split(srcPath)
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	// Заголовки отправляем только с первым байтом архива - до этого ошибку можно вернуть в JSON
+	out := &attachmentWriter{
+		c:           c,
+		filename:    fmt.Sprintf("%s-%s.%s", podName, name, format),
+		contentType: "application/x-tar",
+	}
+	if format == "zip" {
+		out.contentType = "application/zip"
+	}
+
+	var stderr bytes.Buffer
+	command := []string{"tar", "cf", "-", "-C", dir, name}
+
+	var err error
+	if format == "tar" {
+		err = k8s.ExecStream(ctx, cluster, namespace, podName, container, command, nil, out, &stderr)
+	} else {
+		// tar из контейнера перепаковываем в zip на лету
+		pr, pw := io.Pipe()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			pw.CloseWithError(k8s.ExecStream(ctx, cluster, namespace, podName, container, command, nil, pw, &stderr))
+		}()
+
+		err = tarToZip(pr, out)
+		if err != nil {
+			cancel()
+		}
+		pr.CloseWithError(err)
+		<-done
+	}
+
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		if out.started {
+			// Архив уже частично отправлен - остается только оборвать ответ
+			log.Printf("File download from %s/%s failed mid-stream: %v", namespace, podName, err)
+			c.Abort()
+			return
+		}
+
+		status := http.StatusInternalServerError
+		if strings.Contains(stderr.String(), "No such file or directory") {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"error":  err.Error(),
+			"stderr": strings.TrimSpace(stderr.String()),
+		})
 		return
 	}
 
-	// Конвертируем в YAML
-	pod.ManagedFields = nil
-	pod.TypeMeta = metav1.TypeMeta{
-		APIVersion: "v1",
-		Kind:       "Pod",
+	out.start()
+}
+
+// UploadPodFileHandler - загрузка файла (multipart, поле file) в контейнер.
+// ?path=/tmp/ - каталог (имя берется из файла) или полный путь назначения
+func (h *Handler) UploadPodFileHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	podName := c.Param("pod")
+	container := c.Query("container")
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required: " + err.Error()})
+		return
+	}
+
+	destination := c.Query("path")
+	if strings.HasSuffix(destination, "/") {
+		destination += path.Base(fileHeader.Filename)
+	}
+
+	destPath, ok := containerPath(destination)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "path must be an absolute path inside the container"})
+		return
 	}
+	dir, name := path.Split(destPath)
 
-	yamlData, err := yaml.Marshal(pod)
+	container, ok = h.resolveContainer(c, namespace, podName, container)
+	if !ok {
+		return
+	}
+
+	// Multipart больше 32MB gin держит во временном файле, а не в памяти
+	src, err := fileHeader.Open()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	defer src.Close()
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+
+	go func() {
+		tw := tar.NewWriter(pw)
+		err := tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     fileHeader.Size,
+			ModTime:  time.Now(),
+			Typeflag: tar.TypeReg,
+		})
+		if err == nil {
+			_, err = io.Copy(tw, src)
+		}
+		if err == nil {
+			err = tw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	var stderr bytes.Buffer
+	command := []string{"tar", "xf", "-", "-C", dir}
+
+	err = k8s.ExecStream(c.Request.Context(), h.cluster(c), namespace, podName, container, command, pr, nil, &stderr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":  err.Error(),
+			"stderr": strings.TrimSpace(stderr.String()),
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
+		"message":   fmt.Sprintf("File uploaded to %s", destPath),
 		"pod":       podName,
 		"namespace": namespace,
-		"yaml":      string(yamlData),
+		"path":      destPath,
+		"size":      fileHeader.Size,
 	})
 }
 
-func (h *Handler) UpdatePodYAMLHandler(c *gin.Context) {
+func (h *Handler) GetPodYAMLHandler(c *gin.Context) {
 	namespace := c.Param("namespace")
 	podName := c.Param("pod")
 
-	var request struct {
-		YAML string `json:"yaml"`
-	}
-
-	if err := c.BindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
 		return
 	}
 
-	// Декодируем YAML
-	var pod corev1.Pod
-	if err := yaml.Unmarshal([]byte(request.YAML), &pod); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid YAML: " + err.Error()})
+	pod, err := clientset.CoreV1().Pods(namespace).Get(c.Request.Context(), podName, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Проверяем, что имя и namespace совпадают
-	if pod.Name != podName || pod.Namespace != namespace {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Name or namespace mismatch"})
-		return
+	// Конвертируем в YAML
+	pod.ManagedFields = nil
+	pod.TypeMeta = metav1.TypeMeta{
+		APIVersion: "v1",
+		Kind:       "Pod",
 	}
 
-	// Обновляем под
-	_, err := h.clientset.CoreV1().Pods(namespace).Update(c.Request.Context(), &pod, metav1.UpdateOptions{})
+	yamlData, err := sigsyaml.Marshal(pod)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":   "Pod updated successfully",
 		"pod":       podName,
 		"namespace": namespace,
+		"yaml":      string(yamlData),
 	})
 }
 
+func (h *Handler) UpdatePodYAMLHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	podName := c.Param("pod")
+
+	// Server-side apply: неизвестные поля сохраняются, resourceVersion не проверяется.
+	// ?dryRun=All - только валидация, ?force=true - забрать поля у других менеджеров
+	response := h.applyEditedYAML(c, schema.GroupKind{Kind: "Pod"}, namespace, podName)
+	if response == nil {
+		return
+	}
+
+	response["pod"] = podName
+	c.JSON(http.StatusOK, response)
+}
+
 func (h *Handler) GetPodDetailsHandler(c *gin.Context) {
 	namespace := c.Param("namespace")
 	podName := c.Param("pod")
 
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
 		return
 	}
 
-	pod, err := h.clientset.CoreV1().Pods(namespace).Get(c.Request.Context(), podName, metav1.GetOptions{})
+	pod, err := clientset.CoreV1().Pods(namespace).Get(c.Request.Context(), podName, metav1.GetOptions{})
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -247,18 +392,21 @@ func (h *Handler) GetPodDetailsHandler(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"pod":               podName,
-		"namespace":         namespace,
-		"metadata":          pod.ObjectMeta,
-		"spec":              pod.Spec,
-		"status":            pod.Status,
-		"containers":        containers,
-		"containerStatuses": containerStatuses,
-		"nodeName":          pod.Spec.NodeName,
-		"podIP":             pod.Status.PodIP,
-		"hostIP":            pod.Status.HostIP,
-		"startTime":         pod.Status.StartTime,
-		"conditions":        pod.Status.Conditions,
+		"pod":                 podName,
+		"namespace":           namespace,
+		"metadata":            pod.ObjectMeta,
+		"spec":                pod.Spec,
+		"status":              pod.Status,
+		"containers":          containers,
+		"containerStatuses":   containerStatuses,
+		"ephemeralContainers": ephemeralContainersInfo(pod),
+		"nodeName":            pod.Spec.NodeName,
+		"podIP":               pod.Status.PodIP,
+		"hostIP":              pod.Status.HostIP,
+		"startTime":           pod.Status.StartTime,
+		"conditions":          pod.Status.Conditions,
+		"owners":              resolveOwnerChain(c.Request.Context(), clientset, pod),
+		"events":              objectEvents(c.Request.Context(), clientset, namespace, "Pod", podName, pod.UID),
 	})
 }
 
@@ -266,14 +414,15 @@ func (h *Handler) DeletePodHandler(c *gin.Context) {
 	namespace := c.Param("namespace")
 	podName := c.Param("pod")
 
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{
 			"error": "Kubernetes client not initialized",
 		})
 		return
 	}
 
-	err := h.clientset.CoreV1().Pods(namespace).Delete(
+	err := clientset.CoreV1().Pods(namespace).Delete(
 		c.Request.Context(), podName, metav1.DeleteOptions{})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -8,18 +8,63 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	
 	"k8s-manager/internal/k8s"
 )
 
-// PortForwardRequest - структура запроса для port-forward
+// PortForwardRequest - структура запроса для port-forward.
+// Цель - ровно одно из pod, service, deployment, statefulset; для service remotePort - порт сервиса.
 type PortForwardRequest struct {
-	Pod        string `json:"pod" binding:"required"`
-	Namespace  string `json:"namespace" binding:"required"`
-	RemotePort int    `json:"remotePort" binding:"required,min=1,max=65535"`
-	LocalPort  int    `json:"localPort" binding:"required,min=1024,max=65535"`
+	Pod         string `json:"pod"`
+	Service     string `json:"service"`
+	Deployment  string `json:"deployment"`
+	StatefulSet string `json:"statefulset"`
+	Namespace   string `json:"namespace" binding:"required"`
+	RemotePort  int    `json:"remotePort" binding:"required,min=1,max=65535"`
+	LocalPort   int    `json:"localPort" binding:"required,min=1024,max=65535"`
+}
+
+// target - вид и имя цели; ok=false, если задано не ровно одно поле
+func (r *PortForwardRequest) target() (kind, name string, ok bool) {
+	targets := map[string]string{
+		k8s.PortForwardPod:         r.Pod,
+		k8s.PortForwardService:     r.Service,
+		k8s.PortForwardDeployment:  r.Deployment,
+		k8s.PortForwardStatefulSet: r.StatefulSet,
+	}
+	count := 0
+	for targetKind, targetName := range targets {
+		if targetName != "" {
+			kind, name = targetKind, targetName
+			count++
+		}
+	}
+	return kind, name, count == 1
+}
+
+// portForwardSessionJSON - сессия port-forward для ответа API
+func portForwardSessionJSON(session *k8s.PortForwardSession) gin.H {
+	state := session.State()
+	startedAt := ""
+	if !state.StartedAt.IsZero() {
+		startedAt = state.StartedAt.Format(time.RFC3339)
+	}
+	return gin.H{
+		"id":         session.ID,
+		"cluster":    session.Cluster,
+		"target":     session.TargetKind + "/" + session.TargetName,
+		"targetPort": session.TargetPort,
+		"pod":        state.Pod,
+		"namespace":  session.Namespace,
+		"localPort":  session.LocalPort,
+		"remotePort": state.RemotePort,
+		"status":     state.Status,
+		"failovers":  state.Failovers,
+		"lastError":  state.LastError,
+		"createdAt":  session.CreatedAt.Format(time.RFC3339),
+		"startedAt":  startedAt,
+		"url":        session.URL,
+	}
 }
 
 // Валидация имени pod
@@ -67,22 +112,7 @@ func (h *Handler) GetPortForwardSessionsHandler(c *gin.Context) {
 	
 	var result []gin.H
 	for _, session := range sessions {
-		startedAt := ""
-		if !session.StartedAt.IsZero() {
-			startedAt = session.StartedAt.Format(time.RFC3339)
-		}
-		
-		result = append(result, gin.H{
-			"id":         session.ID,
-			"pod":        session.Pod,
-			"namespace":  session.Namespace,
-			"localPort":  session.LocalPort,
-			"remotePort": session.RemotePort,
-			"status":     session.Status,
-			"createdAt":  session.CreatedAt.Format(time.RFC3339),
-			"startedAt":  startedAt,
-			"url":        fmt.Sprintf("http://localhost:%d", session.LocalPort),
-		})
+		result = append(result, portForwardSessionJSON(session))
 	}
 	
 	c.JSON(http.StatusOK, gin.H{
@@ -91,7 +121,8 @@ func (h *Handler) GetPortForwardSessionsHandler(c *gin.Context) {
 	})
 }
 
-// StartPortForwardHandler - запуск port-forward
+// StartPortForwardHandler - запуск port-forward к поду, сервису, deployment или statefulset.
+// Для сервиса и контроллеров выбирается готовый под; если он умирает, сессия переключается на другой.
 func (h *Handler) StartPortForwardHandler(c *gin.Context) {
 	var req PortForwardRequest
 	
@@ -104,11 +135,19 @@ func (h *Handler) StartPortForwardHandler(c *gin.Context) {
 		return
 	}
 	
-	// Валидация pod name
-	if !isValidPodName(req.Pod) {
+	// Валидация цели
+	kind, name, ok := req.target()
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid target",
+			"message": "Exactly one of pod, service, deployment, statefulset is required",
+		})
+		return
+	}
+	if !isValidPodName(name) {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid pod name",
-			"message": "Pod name must match Kubernetes naming conventions (lowercase, numbers, dashes)",
+			"error":   "Invalid " + kind + " name",
+			"message": "Name must match Kubernetes naming conventions (lowercase, numbers, dashes)",
 		})
 		return
 	}
@@ -131,8 +170,11 @@ func (h *Handler) StartPortForwardHandler(c *gin.Context) {
 		return
 	}
 	
+	cluster := h.cluster(c)
+	clientset := h.clientset(c)
+
 	// Проверяем, что clientset инициализирован
-	if h.clientset == nil {
+	if clientset == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Kubernetes client not initialized",
 			"message": "Please check your K8s connection",
@@ -140,38 +182,31 @@ func (h *Handler) StartPortForwardHandler(c *gin.Context) {
 		return
 	}
 	
-	// Проверяем существует ли pod
-	pod, err := h.clientset.CoreV1().Pods(req.Namespace).Get(c.Request.Context(), req.Pod, metav1.GetOptions{})
+	// Выбираем под цели (для пода - проверяем, что он Running)
+	pod, remotePort, err := k8s.ResolvePortForwardPod(c.Request.Context(), clientset, req.Namespace, kind, name, req.RemotePort, "")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Pod not found",
-			"message": fmt.Sprintf("Pod %s/%s does not exist or is not accessible: %v", 
-				req.Namespace, req.Pod, err),
-		})
-		return
-	}
-	
-	// Проверяем, что pod в состоянии Running
-	if pod.Status.Phase != corev1.PodRunning {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Pod not ready",
-			"message": fmt.Sprintf("Pod %s/%s is not running (current status: %s)", 
-				req.Namespace, req.Pod, pod.Status.Phase),
+			"error":   "Target not ready",
+			"message": err.Error(),
 		})
 		return
 	}
 	
 	// Создаем сессию
 	session := &k8s.PortForwardSession{
-		ID:         k8s.GenerateSessionID(req.Namespace, req.Pod, req.RemotePort, req.LocalPort),
-		Pod:        req.Pod,
+		ID:         k8s.GenerateSessionID(cluster.Name, req.Namespace, kind+"-"+name, req.RemotePort, req.LocalPort),
+		Cluster:    cluster.Name,
+		Pod:        pod,
 		Namespace:  req.Namespace,
 		LocalPort:  req.LocalPort,
-		RemotePort: req.RemotePort,
+		RemotePort: remotePort,
 		Status:     "starting",
 		CreatedAt:  time.Now(),
 		URL:        fmt.Sprintf("http://localhost:%d", req.LocalPort),
 		StopChan:   make(chan struct{}),
+		TargetKind: kind,
+		TargetName: name,
+		TargetPort: req.RemotePort,
 	}
 	
 	// Добавляем сессию в менеджер
@@ -180,36 +215,30 @@ func (h *Handler) StartPortForwardHandler(c *gin.Context) {
 	
 	// Запускаем port-forward в горутине
 	go func() {
-		k8s.StartPortForward(session, h.clientset)
+		k8s.StartPortForward(session, cluster)
 	}()
 	
-	// Даем время на запуск
-	time.Sleep(500 * time.Millisecond)
-	
-	// Проверяем статус
-	updatedSession, exists := manager.GetSession(session.ID)
-	if !exists || updatedSession.Status != "running" {
+	// Ждем первого подключения; отказ на старте (порт, права, под) возвращаем сразу
+	state := session.WaitStarted()
+	if state.Status != "running" {
+		session.Stop()
 		manager.RemoveSession(session.ID)
+		message := "Failed to establish port-forward connection"
+		if state.LastError != "" {
+			message = state.LastError
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Port-forward failed to start",
-			"message": "Failed to establish port-forward connection",
+			"message": message,
 		})
 		return
 	}
 	
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"session": gin.H{
-			"id":         session.ID,
-			"pod":        session.Pod,
-			"namespace":  session.Namespace,
-			"localPort":  session.LocalPort,
-			"remotePort": session.RemotePort,
-			"status":     updatedSession.Status,
-			"url":        session.URL,
-		},
-		"message": fmt.Sprintf("Port-forward started successfully: localhost:%d → %s/%s:%d",
-			session.LocalPort, session.Namespace, session.Pod, session.RemotePort),
+		"session": portForwardSessionJSON(session),
+		"message": fmt.Sprintf("Port-forward started successfully: localhost:%d → %s/%s:%d (pod %s:%d)",
+			session.LocalPort, session.Namespace, kind+"/"+name, req.RemotePort, state.Pod, state.RemotePort),
 	})
 }
 
@@ -242,10 +271,12 @@ func (h *Handler) StopPortForwardHandler(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
 			"message": fmt.Sprintf("Port-forward stopped for %s/%s", 
-				session.Namespace, session.Pod),
+				session.Namespace, session.TargetKind+"/"+session.TargetName),
 			"session": gin.H{
 				"id":        sessionID,
-				"pod":       session.Pod,
+				"cluster":   session.Cluster,
+				"target":    session.TargetKind + "/" + session.TargetName,
+				"pod":       session.State().Pod,
 				"namespace": session.Namespace,
 				"status":    "stopped",
 			},
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+func (h *Handler) GetReplicaSetsHandler(c *gin.Context) {
+	namespace := c.DefaultQuery("namespace", "market")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	replicaSets, err := h.resources(c).ListReplicaSets(c.Request.Context(), namespace, liveRead(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var result []gin.H
+	for _, rs := range replicaSets.Items {
+		replicas := int32(0)
+		if rs.Spec.Replicas != nil {
+			replicas = *rs.Spec.Replicas
+		}
+
+		// Владелец (обычно Deployment)
+		owner := gin.H{}
+		if ref := controllerRef(rs.OwnerReferences); ref != nil {
+			owner = gin.H{"kind": ref.Kind, "name": ref.Name}
+		}
+
+		result = append(result, gin.H{
+			"name":        rs.Name,
+			"namespace":   rs.Namespace,
+			"ready":       fmt.Sprintf("%d/%d", rs.Status.ReadyReplicas, replicas),
+			"ready_count": rs.Status.ReadyReplicas,
+			"total_count": replicas,
+			"available":   rs.Status.AvailableReplicas,
+			"revision":    rs.Annotations[revisionAnnotation],
+			"owner":       owner,
+			"age":         time.Since(rs.CreationTimestamp.Time).Round(time.Second).String(),
+			"labels":      rs.Labels,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"namespace":   namespace,
+		"count":       len(replicaSets.Items),
+		"replicasets": result,
+		"source":      readSource(c),
+	})
+}
+
+func (h *Handler) GetReplicaSetYAMLHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	rs, err := clientset.AppsV1().ReplicaSets(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	rs.ManagedFields = nil
+	rs.TypeMeta = metav1.TypeMeta{
+		APIVersion: "apps/v1",
+		Kind:       "ReplicaSet",
+	}
+
+	yamlData, err := sigsyaml.Marshal(rs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"name":      name,
+		"namespace": namespace,
+		"yaml":      string(yamlData),
+	})
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"k8s-manager/internal/k8s"
+)
+
+// coreGroup - имя пустой (core) группы API в путях /resources/:group/...
+const coreGroup = "core"
+
+// GetAPIResourcesHandler - все виды ресурсов кластера из discovery, включая CRD
+func (h *Handler) GetAPIResourcesHandler(c *gin.Context) {
+	cluster := h.cluster(c)
+	if cluster == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	resources, err := cluster.APIResources()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := []gin.H{}
+	for _, resource := range resources {
+		group := resource.Group
+		if group == "" {
+			group = coreGroup
+		}
+		result = append(result, gin.H{
+			"group":      resource.Group,
+			"version":    resource.Version,
+			"resource":   resource.Resource,
+			"kind":       resource.Kind,
+			"namespaced": resource.Namespaced,
+			"verbs":      resource.Verbs,
+			"shortNames": resource.ShortNames,
+			"path":       h.apiLink(c, "/resources/"+group+"/"+resource.Version+"/"+resource.Resource),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":     len(result),
+		"resources": result,
+	})
+}
+
+// resourceTarget - кластер и mapping ресурса из /resources/:group/:version/:resource (core - пустая группа).
+// ok=false, если ответ с ошибкой уже записан.
+func (h *Handler) resourceTarget(c *gin.Context) (*k8s.Cluster, *meta.RESTMapping, bool) {
+	cluster := h.cluster(c)
+	if cluster == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return nil, nil, false
+	}
+
+	group := c.Param("group")
+	if group == coreGroup {
+		group = ""
+	}
+	gvr := schema.GroupVersionResource{Group: group, Version: c.Param("version"), Resource: c.Param("resource")}
+
+	mapping, err := cluster.ResourceMapping(gvr)
+	if err != nil {
+		apiError(c, err)
+		return nil, nil, false
+	}
+	return cluster, mapping, true
+}
+
+// resourceNamespace - ?namespace= для namespaced ресурсов, пусто для ресурсов уровня кластера
+func resourceNamespace(c *gin.Context, mapping *meta.RESTMapping, fallback string) string {
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return ""
+	}
+	return c.DefaultQuery("namespace", fallback)
+}
+
+// ListResourcesHandler - список объектов любого ресурса; для CRD - с колонками additionalPrinterColumns.
+// ?namespace=all&labelSelector=&fieldSelector=
+func (h *Handler) ListResourcesHandler(c *gin.Context) {
+	cluster, mapping, ok := h.resourceTarget(c)
+	if !ok {
+		return
+	}
+
+	namespace := resourceNamespace(c, mapping, "all")
+	listNamespace := namespace
+	if namespace == "all" {
+		listNamespace = ""
+	}
+
+	ctx := c.Request.Context()
+
+	list, err := cluster.ResourceClient(mapping, listNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: c.Query("labelSelector"),
+		FieldSelector: c.Query("fieldSelector"),
+	})
+	if err != nil {
+		apiError(c, err)
+		return
+	}
+
+	columns, err := cluster.PrinterColumns(ctx, mapping.Resource)
+	if err != nil || columns == nil {
+		// Встроенный ресурс или CRD недоступен для чтения - список полезен и без колонок
+		columns = []k8s.PrinterColumn{}
+	}
+
+	items := []gin.H{}
+	for i := range list.Items {
+		obj := &list.Items[i]
+		items = append(items, gin.H{
+			"name":      obj.GetName(),
+			"namespace": obj.GetNamespace(),
+			"labels":    obj.GetLabels(),
+			"age":       time.Since(obj.GetCreationTimestamp().Time).Round(time.Second).String(),
+			"cells":     k8s.PrinterCells(obj, columns),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"group":      mapping.Resource.Group,
+		"version":    mapping.Resource.Version,
+		"resource":   mapping.Resource.Resource,
+		"kind":       mapping.GroupVersionKind.Kind,
+		"namespaced": mapping.Scope.Name() == meta.RESTScopeNameNamespace,
+		"namespace":  namespace,
+		"columns":    columns,
+		"count":      len(items),
+		"items":      items,
+	})
+}
+
+// GetResourceYAMLHandler - YAML объекта любого ресурса. ?namespace=default
+func (h *Handler) GetResourceYAMLHandler(c *gin.Context) {
+	cluster, mapping, ok := h.resourceTarget(c)
+	if !ok {
+		return
+	}
+	namespace := resourceNamespace(c, mapping, "default")
+	name := c.Param("name")
+
+	obj, err := cluster.ResourceClient(mapping, namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		apiError(c, err)
+		return
+	}
+
+	yamlData, err := objectYAML(obj)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"kind":      mapping.GroupVersionKind.Kind,
+		"name":      name,
+		"namespace": namespace,
+		"yaml":      yamlData,
+	})
+}
+
+// UpdateResourceYAMLHandler - server-side apply YAML объекта любого ресурса.
+// ?namespace=default&dryRun=All&force=true
+func (h *Handler) UpdateResourceYAMLHandler(c *gin.Context) {
+	_, mapping, ok := h.resourceTarget(c)
+	if !ok {
+		return
+	}
+	namespace := resourceNamespace(c, mapping, "default")
+	name := c.Param("name")
+
+	response := h.applyEditedYAML(c, mapping.GroupVersionKind.GroupKind(), namespace, name)
+	if response == nil {
+		return
+	}
+
+	response["name"] = name
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteResourceHandler - удаление объекта любого ресурса. ?namespace=default&propagation=Background
+func (h *Handler) DeleteResourceHandler(c *gin.Context) {
+	cluster, mapping, ok := h.resourceTarget(c)
+	if !ok {
+		return
+	}
+	namespace := resourceNamespace(c, mapping, "default")
+	name := c.Param("name")
+
+	propagation, ok := parsePropagationPolicy(c.DefaultQuery("propagation", "Background"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid propagation policy (Background, Foreground, Orphan)"})
+		return
+	}
+
+	err := cluster.ResourceClient(mapping, namespace).Delete(c.Request.Context(), name, metav1.DeleteOptions{
+		PropagationPolicy: &propagation,
+	})
+	if err != nil {
+		apiError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     mapping.GroupVersionKind.Kind + " deleted successfully",
+		"name":        name,
+		"namespace":   namespace,
+		"propagation": string(propagation),
+	})
+}
@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"k8s-manager/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+const (
+	revisionAnnotation    = "deployment.kubernetes.io/revision"
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+)
+
+// deploymentRevision - ReplicaSet деплоймента с номером ревизии
+type deploymentRevision struct {
+	Revision   int64
+	ReplicaSet appsv1.ReplicaSet
+}
+
+// GetDeploymentHistoryHandler - история ревизий деплоймента (kubectl rollout history)
+func (h *Handler) GetDeploymentHistoryHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	revisions, err := deploymentRevisions(c.Request.Context(), clientset, deployment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	currentRevision := deployment.Annotations[revisionAnnotation]
+
+	history := []gin.H{}
+	for i, rev := range revisions {
+		rs := rev.ReplicaSet
+
+		images := []string{}
+		for _, container := range rs.Spec.Template.Spec.Containers {
+			images = append(images, container.Image)
+		}
+
+		replicas := int32(0)
+		if rs.Spec.Replicas != nil {
+			replicas = *rs.Spec.Replicas
+		}
+
+		entry := gin.H{
+			"revision":     rev.Revision,
+			"replicaset":   rs.Name,
+			"change_cause": rs.Annotations[changeCauseAnnotation],
+			"images":       images,
+			"replicas":     replicas,
+			"ready":        rs.Status.ReadyReplicas,
+			"current":      strconv.FormatInt(rev.Revision, 10) == currentRevision,
+			"created":      rs.CreationTimestamp.Format(time.RFC3339),
+		}
+
+		// Diff шаблона относительно предыдущей ревизии
+		if i > 0 {
+			diff, err := templateDiff(&revisions[i-1].ReplicaSet.Spec.Template, &rs.Spec.Template,
+				fmt.Sprintf("revision %d", revisions[i-1].Revision), fmt.Sprintf("revision %d", rev.Revision))
+			if err != nil {
+				entry["diff_error"] = err.Error()
+			} else {
+				entry["diff"] = diff
+			}
+		}
+
+		history = append(history, entry)
+	}
+
+	response := gin.H{
+		"name":             name,
+		"namespace":        namespace,
+		"current_revision": currentRevision,
+		"paused":           deployment.Spec.Paused,
+		"count":            len(history),
+		"revisions":        history,
+	}
+
+	// Произвольное сравнение ревизий: ?from=N&to=M
+	if fromStr, toStr := c.Query("from"), c.Query("to"); fromStr != "" && toStr != "" {
+		from, errFrom := strconv.ParseInt(fromStr, 10, 64)
+		to, errTo := strconv.ParseInt(toStr, 10, 64)
+		fromRev, okFrom := findRevision(revisions, from)
+		toRev, okTo := findRevision(revisions, to)
+		if errFrom != nil || errTo != nil || !okFrom || !okTo {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from/to revision"})
+			return
+		}
+
+		diff, err := templateDiff(&fromRev.ReplicaSet.Spec.Template, &toRev.ReplicaSet.Spec.Template,
+			fmt.Sprintf("revision %d", from), fmt.Sprintf("revision %d", to))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		response["diff"] = gin.H{"from": from, "to": to, "unified": diff}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RollbackDeploymentHandler - откат на ревизию ?revision=N (по умолчанию - предыдущая)
+func (h *Handler) RollbackDeploymentHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	revision := int64(0)
+	if revStr := c.Query("revision"); revStr != "" {
+		var err error
+		revision, err = strconv.ParseInt(revStr, 10, 64)
+		if err != nil || revision < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision value"})
+			return
+		}
+	}
+
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Deployment not found: " + err.Error()})
+		return
+	}
+
+	if deployment.Spec.Paused {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot rollback a paused deployment, resume it first"})
+		return
+	}
+
+	revisions, err := deploymentRevisions(c.Request.Context(), clientset, deployment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var target deploymentRevision
+	var found bool
+	if revision == 0 {
+		// Предыдущая ревизия - вторая с конца
+		if len(revisions) >= 2 {
+			target, found = revisions[len(revisions)-2], true
+		}
+	} else {
+		target, found = findRevision(revisions, revision)
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		return
+	}
+
+	if strconv.FormatInt(target.Revision, 10) == deployment.Annotations[revisionAnnotation] {
+		c.JSON(http.StatusOK, gin.H{
+			"message":  fmt.Sprintf("Deployment %s is already at revision %d", name, target.Revision),
+			"revision": target.Revision,
+			"skipped":  true,
+		})
+		return
+	}
+
+	// Восстанавливаем шаблон без служебного pod-template-hash
+	template := target.ReplicaSet.Spec.Template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	deployment.Spec.Template = *template
+
+	if cause, ok := target.ReplicaSet.Annotations[changeCauseAnnotation]; ok {
+		if deployment.Annotations == nil {
+			deployment.Annotations = make(map[string]string)
+		}
+		deployment.Annotations[changeCauseAnnotation] = cause
+	}
+
+	_, err = clientset.AppsV1().Deployments(namespace).Update(c.Request.Context(), deployment, metav1.UpdateOptions{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    fmt.Sprintf("Deployment %s rolled back to revision %d", name, target.Revision),
+		"deployment": name,
+		"namespace":  namespace,
+		"revision":   target.Revision,
+	})
+}
+
+func (h *Handler) PauseDeploymentHandler(c *gin.Context) {
+	h.setDeploymentPaused(c, true)
+}
+
+func (h *Handler) ResumeDeploymentHandler(c *gin.Context) {
+	h.setDeploymentPaused(c, false)
+}
+
+func (h *Handler) setDeploymentPaused(c *gin.Context, paused bool) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Deployment not found: " + err.Error()})
+		return
+	}
+
+	deployment.Spec.Paused = paused
+	_, err = clientset.AppsV1().Deployments(namespace).Update(c.Request.Context(), deployment, metav1.UpdateOptions{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	action := "resumed"
+	if paused {
+		action = "paused"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    fmt.Sprintf("Deployment %s rollout %s", name, action),
+		"deployment": name,
+		"namespace":  namespace,
+		"paused":     paused,
+	})
+}
+
+// deploymentRevisions - ReplicaSet'ы деплоймента, отсортированные по ревизии
+func deploymentRevisions(ctx context.Context, clientset *kubernetes.Clientset, deployment *appsv1.Deployment) ([]deploymentRevision, error) {
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	replicaSets, err := clientset.AppsV1().ReplicaSets(deployment.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []deploymentRevision
+	for _, rs := range replicaSets.Items {
+		ref := controllerRef(rs.OwnerReferences)
+		if ref == nil || ref.UID != deployment.UID {
+			continue
+		}
+
+		revision, err := strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+		if err != nil {
+			continue
+		}
+		revisions = append(revisions, deploymentRevision{Revision: revision, ReplicaSet: rs})
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+
+	return revisions, nil
+}
+
+func findRevision(revisions []deploymentRevision, revision int64) (deploymentRevision, bool) {
+	for _, rev := range revisions {
+		if rev.Revision == revision {
+			return rev, true
+		}
+	}
+	return deploymentRevision{}, false
+}
+
+// templateDiff - unified diff двух шаблонов подов без pod-template-hash
+func templateDiff(from, to *corev1.PodTemplateSpec, fromName, toName string) (string, error) {
+	fromYAML, err := templateYAML(from)
+	if err != nil {
+		return "", err
+	}
+	toYAML, err := templateYAML(to)
+	if err != nil {
+		return "", err
+	}
+	return utils.UnifiedDiff(fromYAML, toYAML, fromName, toName), nil
+}
+
+func templateYAML(template *corev1.PodTemplateSpec) (string, error) {
+	clean := template.DeepCopy()
+	delete(clean.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+
+	data, err := sigsyaml.Marshal(clean)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
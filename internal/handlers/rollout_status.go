@@ -0,0 +1,428 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	defaultRolloutTimeout = 10 * time.Minute
+	rolloutPollInterval   = time.Second
+	rewatchDelay          = 2 * time.Second
+)
+
+// stuckWaitingReasons - причины ожидания контейнера, при которых rollout сам не продвинется
+var stuckWaitingReasons = map[string]bool{
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CrashLoopBackOff":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+// RolloutStatus - снимок состояния rollout'а деплоймента
+type RolloutStatus struct {
+	Revision    string     `json:"revision"`
+	ReplicaSet  string     `json:"replicaset,omitempty"`
+	Replicas    int32      `json:"replicas"`
+	Updated     int32      `json:"updated"`
+	Ready       int32      `json:"ready"`
+	Available   int32      `json:"available"`
+	Total       int32      `json:"total"`
+	Paused      bool       `json:"paused"`
+	Done        bool       `json:"done"`
+	Failed      bool       `json:"failed"`
+	Message     string     `json:"message"`
+	PodProblems []PodIssue `json:"pod_problems,omitempty"`
+}
+
+// PodIssue - контейнер нового ReplicaSet, застрявший в ImagePull/CrashLoop
+type PodIssue struct {
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message,omitempty"`
+	Restarts  int32  `json:"restarts"`
+}
+
+// WatchRolloutStatusHandler - WebSocket или SSE с ходом rollout'а деплоймента (kubectl rollout status)
+func (h *Handler) WatchRolloutStatusHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	timeout := defaultRolloutTimeout
+	if timeoutStr := c.Query("timeout"); timeoutStr != "" {
+		seconds, err := strconv.Atoi(timeoutStr)
+		if err != nil || seconds <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid timeout value"})
+			return
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// WebSocket или SSE (EventSource, curl)
+	sink, err := openEventSink(c)
+	if err != nil {
+		log.Printf("WebSocket upgrade error: %v", err)
+		return
+	}
+	defer sink.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Отключение клиента останавливает наблюдение
+	go func() {
+		select {
+		case <-sink.Closed():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	send := func(msg LogMessage) error {
+		return sink.Send("", msg)
+	}
+
+	pingTicker := time.NewTicker(sink.PingInterval())
+	defer pingTicker.Stop()
+
+	send(LogMessage{
+		Type:    "info",
+		Message: fmt.Sprintf("Watching rollout of deployment %s/%s (timeout: %s)", namespace, name, timeout),
+		Time:    time.Now().Format(time.RFC3339),
+	})
+
+	notify, err := watchRolloutObjects(ctx, clientset, namespace, name, selector.String())
+	if err != nil {
+		send(LogMessage{
+			Type:    "error",
+			Message: fmt.Sprintf("Failed to watch rollout: %v", err),
+			Time:    time.Now().Format(time.RFC3339),
+		})
+		return
+	}
+
+	ticker := time.NewTicker(rolloutPollInterval)
+	defer ticker.Stop()
+
+	// События watch'ей только помечают состояние устаревшим - пересчет не чаще раза в секунду
+	dirty := true
+	lastMessage := ""
+	reported := make(map[string]bool)
+
+	for {
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				send(LogMessage{
+					Type:    "verdict",
+					Message: fmt.Sprintf("Rollout of %s/%s did not finish within %s", namespace, name, timeout),
+					Data:    gin.H{"result": "timeout"},
+					Time:    time.Now().Format(time.RFC3339),
+				})
+			}
+			return
+
+		case <-notify:
+			dirty = true
+
+		case <-pingTicker.C:
+			if err := sink.Ping(); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			if !dirty {
+				continue
+			}
+			dirty = false
+
+			status, err := evaluateRollout(ctx, clientset, namespace, name)
+			if err != nil {
+				if apierrors.IsNotFound(err) {
+					send(LogMessage{
+						Type:    "verdict",
+						Message: fmt.Sprintf("Deployment %s/%s was deleted", namespace, name),
+						Data:    gin.H{"result": "failed"},
+						Time:    time.Now().Format(time.RFC3339),
+					})
+					return
+				}
+				if ctx.Err() != nil {
+					continue
+				}
+				send(LogMessage{
+					Type:    "error",
+					Message: fmt.Sprintf("Failed to get rollout status: %v", err),
+					Time:    time.Now().Format(time.RFC3339),
+				})
+				dirty = true
+				continue
+			}
+
+			// Каждую проблему пода сообщаем один раз
+			for _, issue := range status.PodProblems {
+				key := issue.Pod + "/" + issue.Container + "/" + issue.Reason
+				if reported[key] {
+					continue
+				}
+				reported[key] = true
+
+				send(LogMessage{
+					Type:    "warning",
+					Message: fmt.Sprintf("Pod %s container %s: %s", issue.Pod, issue.Container, issue.Reason),
+					Data:    issue,
+					Time:    time.Now().Format(time.RFC3339),
+				})
+			}
+
+			if status.Message != lastMessage {
+				lastMessage = status.Message
+				if err := send(LogMessage{
+					Type:    "progress",
+					Message: status.Message,
+					Data:    status,
+					Time:    time.Now().Format(time.RFC3339),
+				}); err != nil {
+					return
+				}
+			}
+
+			if status.Done || status.Failed {
+				result := "success"
+				if status.Failed {
+					result = "failed"
+				}
+				send(LogMessage{
+					Type:    "verdict",
+					Message: status.Message,
+					Data:    gin.H{"result": result, "status": status},
+					Time:    time.Now().Format(time.RFC3339),
+				})
+				return
+			}
+		}
+	}
+}
+
+// watchRolloutObjects - watch деплоймента, его ReplicaSet'ов и подов; сигнал в канал на любое событие
+func watchRolloutObjects(ctx context.Context, clientset *kubernetes.Clientset, namespace, name, selector string) (<-chan struct{}, error) {
+	openers := []func() (watch.Interface, error){
+		func() (watch.Interface, error) {
+			return clientset.AppsV1().Deployments(namespace).Watch(ctx, metav1.ListOptions{
+				FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String(),
+			})
+		},
+		func() (watch.Interface, error) {
+			return clientset.AppsV1().ReplicaSets(namespace).Watch(ctx, metav1.ListOptions{LabelSelector: selector})
+		},
+		func() (watch.Interface, error) {
+			return clientset.CoreV1().Pods(namespace).Watch(ctx, metav1.ListOptions{LabelSelector: selector})
+		},
+	}
+
+	watchers := make([]watch.Interface, 0, len(openers))
+	for _, open := range openers {
+		w, err := open()
+		if err != nil {
+			for _, started := range watchers {
+				started.Stop()
+			}
+			return nil, err
+		}
+		watchers = append(watchers, w)
+	}
+
+	notify := make(chan struct{}, 1)
+	for i, w := range watchers {
+		go func(w watch.Interface, open func() (watch.Interface, error)) {
+			for {
+				select {
+				case <-ctx.Done():
+					w.Stop()
+					return
+
+				case _, ok := <-w.ResultChan():
+					if ok {
+						select {
+						case notify <- struct{}{}:
+						default:
+						}
+						continue
+					}
+
+					// API-сервер периодически закрывает watch - переоткрываем
+					w.Stop()
+					for {
+						select {
+						case <-ctx.Done():
+							return
+						case <-time.After(rewatchDelay):
+						}
+						var err error
+						if w, err = open(); err == nil {
+							break
+						}
+						log.Printf("Rollout re-watch error: %v", err)
+					}
+				}
+			}
+		}(w, openers[i])
+	}
+
+	return notify, nil
+}
+
+// evaluateRollout - состояние rollout'а по правилам kubectl rollout status
+func evaluateRollout(ctx context.Context, clientset *kubernetes.Clientset, namespace, name string) (*RolloutStatus, error) {
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	status := &RolloutStatus{
+		Revision:  deployment.Annotations[revisionAnnotation],
+		Replicas:  replicas,
+		Updated:   deployment.Status.UpdatedReplicas,
+		Ready:     deployment.Status.ReadyReplicas,
+		Available: deployment.Status.AvailableReplicas,
+		Total:     deployment.Status.Replicas,
+		Paused:    deployment.Spec.Paused,
+	}
+
+	newRS, err := newReplicaSet(ctx, clientset, deployment)
+	if err != nil {
+		return nil, err
+	}
+	if newRS != nil {
+		status.ReplicaSet = newRS.Name
+		status.PodProblems, err = replicaSetPodIssues(ctx, clientset, newRS)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case deployment.Generation > deployment.Status.ObservedGeneration:
+		status.Message = "Waiting for deployment spec update to be observed..."
+	case progressDeadlineExceeded(deployment):
+		status.Failed = true
+		status.Message = fmt.Sprintf("Deployment %q exceeded its progress deadline", name)
+	case status.Updated < replicas:
+		status.Message = fmt.Sprintf("Waiting for rollout to finish: %d out of %d new replicas have been updated...", status.Updated, replicas)
+	case status.Total > status.Updated:
+		status.Message = fmt.Sprintf("Waiting for rollout to finish: %d old replicas are pending termination...", status.Total-status.Updated)
+	case status.Available < status.Updated:
+		status.Message = fmt.Sprintf("Waiting for rollout to finish: %d of %d updated replicas are available...", status.Available, status.Updated)
+	default:
+		status.Done = true
+		status.Message = fmt.Sprintf("Deployment %q successfully rolled out", name)
+	}
+
+	if status.Paused && !status.Done && !status.Failed {
+		status.Message += " (rollout is paused)"
+	}
+
+	return status, nil
+}
+
+func progressDeadlineExceeded(deployment *appsv1.Deployment) bool {
+	for _, cond := range deployment.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Reason == "ProgressDeadlineExceeded" {
+			return true
+		}
+	}
+	return false
+}
+
+// newReplicaSet - ReplicaSet текущей ревизии деплоймента
+func newReplicaSet(ctx context.Context, clientset *kubernetes.Clientset, deployment *appsv1.Deployment) (*appsv1.ReplicaSet, error) {
+	revisions, err := deploymentRevisions(ctx, clientset, deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	current := deployment.Annotations[revisionAnnotation]
+	for i := range revisions {
+		if strconv.FormatInt(revisions[i].Revision, 10) == current {
+			return &revisions[i].ReplicaSet, nil
+		}
+	}
+	return nil, nil
+}
+
+// replicaSetPodIssues - контейнеры подов ReplicaSet'а в ImagePull/CrashLoop
+func replicaSetPodIssues(ctx context.Context, clientset *kubernetes.Clientset, rs *appsv1.ReplicaSet) ([]PodIssue, error) {
+	selector, err := metav1.LabelSelectorAsSelector(rs.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := clientset.CoreV1().Pods(rs.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []PodIssue
+	for _, pod := range pods.Items {
+		ref := controllerRef(pod.OwnerReferences)
+		if ref == nil || ref.UID != rs.UID {
+			continue
+		}
+
+		statuses := append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...)
+		statuses = append(statuses, pod.Status.ContainerStatuses...)
+		for _, cs := range statuses {
+			if cs.State.Waiting == nil || !stuckWaitingReasons[cs.State.Waiting.Reason] {
+				continue
+			}
+			issues = append(issues, PodIssue{
+				Pod:       pod.Name,
+				Container: cs.Name,
+				Reason:    cs.State.Waiting.Reason,
+				Message:   cs.State.Waiting.Message,
+				Restarts:  cs.RestartCount,
+			})
+		}
+	}
+
+	return issues, nil
+}
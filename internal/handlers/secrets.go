@@ -11,12 +11,13 @@ import (
 func (h *Handler) GetSecretsHandler(c *gin.Context) {
 	namespace := c.Param("namespace")
 
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
 		return
 	}
 
-	secrets, err := h.clientset.CoreV1().Secrets(namespace).List(c.Request.Context(), metav1.ListOptions{})
+	secrets, err := clientset.CoreV1().Secrets(namespace).List(c.Request.Context(), metav1.ListOptions{})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -2,62 +2,211 @@ package handlers
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"k8s-manager/internal/k8s"
 )
 
+// GetServicesHandler - сервисы с адресами из EndpointSlice, выбранными подами и проблемами конфигурации
+// (селектор без подов, targetPort без контейнерного порта, нет готовых endpoints)
 func (h *Handler) GetServicesHandler(c *gin.Context) {
 	namespace := c.DefaultQuery("namespace", "default")
 
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
 		return
 	}
 
-	services, err := h.clientset.CoreV1().Services(namespace).List(c.Request.Context(), metav1.ListOptions{})
+	ctx := c.Request.Context()
+	cache := h.resources(c)
+
+	services, err := cache.ListServices(ctx, namespace, liveRead(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Endpoints и поды нужны для диагностики; без них список сервисов все равно отдаем
+	var endpoints map[string]*k8s.ServiceEndpoints
+	if slices, err := cache.ListEndpointSlices(ctx, namespace, liveRead(c)); err == nil {
+		endpoints = k8s.IndexEndpointSlices(slices.Items)
+	} else {
+		log.Printf("⚠️  Failed to list endpoint slices in %s: %v", namespace, err)
+	}
+	var pods []corev1.Pod
+	if podList, err := cache.ListPods(ctx, namespace, liveRead(c)); err == nil {
+		pods = podList.Items
+	} else {
+		log.Printf("⚠️  Failed to list pods in %s: %v", namespace, err)
+	}
+
 	var result []gin.H
-	for _, svc := range services.Items {
+	misconfigured := 0
+	for i := range services.Items {
+		svc := &services.Items[i]
+
 		ports := []string{}
 		for _, port := range svc.Spec.Ports {
 			ports = append(ports, fmt.Sprintf("%d/%s", port.Port, port.Protocol))
 		}
 
+		svcEndpoints := endpoints[svc.Namespace+"/"+svc.Name]
+		if svcEndpoints == nil {
+			svcEndpoints = &k8s.ServiceEndpoints{Addresses: []k8s.EndpointAddress{}, Ports: []k8s.EndpointPort{}}
+		}
+
+		ready := []k8s.EndpointAddress{}
+		notReady := []k8s.EndpointAddress{}
+		for _, address := range svcEndpoints.Addresses {
+			if address.Ready {
+				ready = append(ready, address)
+			} else {
+				notReady = append(notReady, address)
+			}
+		}
+
+		selected := selectedPods(svc, pods)
+		selectedNames := []string{}
+		for _, pod := range selected {
+			selectedNames = append(selectedNames, pod.Name)
+		}
+
+		// Без списка EndpointSlice готовность не проверяем
+		var knownEndpoints *k8s.ServiceEndpoints
+		if endpoints != nil {
+			knownEndpoints = svcEndpoints
+		}
+		issues := serviceIssues(svc, selected, knownEndpoints, pods != nil)
+		if len(issues) > 0 {
+			misconfigured++
+		}
+
 		result = append(result, gin.H{
 			"name":      svc.Name,
 			"namespace": svc.Namespace,
 			"type":      string(svc.Spec.Type),
 			"ports":     ports,
 			"clusterIP": svc.Spec.ClusterIP,
-			"age":       time.Since(svc.CreationTimestamp.Time).Round(time.Second).String(),
+			"selector":  svc.Spec.Selector,
+			"endpoints": gin.H{
+				"ready":             svcEndpoints.Ready,
+				"notReady":          svcEndpoints.NotReady,
+				"readyAddresses":    ready,
+				"notReadyAddresses": notReady,
+				"ports":             svcEndpoints.Ports,
+			},
+			"pods":   selectedNames,
+			"issues": issues,
+			"age":    time.Since(svc.CreationTimestamp.Time).Round(time.Second).String(),
 		})
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"namespace": namespace,
-		"count":     len(services.Items),
-		"services":  result,
+		"namespace":     namespace,
+		"count":         len(services.Items),
+		"misconfigured": misconfigured,
+		"services":      result,
+		"source":        readSource(c),
 	})
 }
 
+// selectedPods - поды, которые выбирает селектор сервиса (без селектора - ни одного)
+func selectedPods(svc *corev1.Service, pods []corev1.Pod) []corev1.Pod {
+	if len(svc.Spec.Selector) == 0 {
+		return nil
+	}
+
+	selector := labels.SelectorFromSet(svc.Spec.Selector)
+	var selected []corev1.Pod
+	for _, pod := range pods {
+		if pod.Namespace == svc.Namespace && selector.Matches(labels.Set(pod.Labels)) {
+			selected = append(selected, pod)
+		}
+	}
+	return selected
+}
+
+// serviceIssues - типичные причины "сервис не работает": селектор не выбирает поды,
+// targetPort не совпадает с портами контейнеров, нет готовых endpoints
+func serviceIssues(svc *corev1.Service, selected []corev1.Pod, endpoints *k8s.ServiceEndpoints, podsKnown bool) []gin.H {
+	issues := []gin.H{}
+	addIssue := func(issueType, severity, message string) {
+		issues = append(issues, gin.H{"type": issueType, "severity": severity, "message": message})
+	}
+
+	// ExternalName и сервисы без селектора (endpoints ведутся вручную) не проверяем
+	if svc.Spec.Type == corev1.ServiceTypeExternalName || len(svc.Spec.Selector) == 0 || !podsKnown {
+		return issues
+	}
+
+	if len(selected) == 0 {
+		addIssue("selector-mismatch", "error", fmt.Sprintf("selector %s matches no pods", labels.SelectorFromSet(svc.Spec.Selector)))
+		return issues
+	}
+
+	for _, port := range svc.Spec.Ports {
+		target := port.TargetPort
+		if target.Type == intstr.Int && target.IntVal == 0 {
+			target = intstr.FromInt32(port.Port)
+		}
+
+		if podsExposePort(selected, target) {
+			continue
+		}
+		if target.Type == intstr.String {
+			// Именованный порт без контейнера с таким именем не попадет в endpoints
+			addIssue("target-port-mismatch", "error", fmt.Sprintf("port %d: named targetPort %q is not defined by any selected pod", port.Port, target.StrVal))
+		} else {
+			// Порт может слушаться и без объявления в containerPort, поэтому это предупреждение
+			addIssue("target-port-mismatch", "warning", fmt.Sprintf("port %d: targetPort %d is not declared by any container of selected pods", port.Port, target.IntVal))
+		}
+	}
+
+	if endpoints != nil && endpoints.Ready == 0 {
+		addIssue("no-ready-endpoints", "error", fmt.Sprintf("%d pod(s) selected, none ready", len(selected)))
+	}
+
+	return issues
+}
+
+// podsExposePort - хотя бы один под объявляет контейнерный порт с этим номером или именем
+func podsExposePort(pods []corev1.Pod, target intstr.IntOrString) bool {
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			for _, port := range container.Ports {
+				if target.Type == intstr.String && port.Name == target.StrVal {
+					return true
+				}
+				if target.Type == intstr.Int && port.ContainerPort == target.IntVal {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 func (h *Handler) GetServiceYAMLHandler(c *gin.Context) {
 	namespace := c.Param("namespace")
 	name := c.Param("name")
 
-	if h.clientset == nil {
+	clientset := h.clientset(c)
+	if clientset == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
 		return
 	}
 
-	service, err := h.clientset.CoreV1().Services(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
+	service, err := clientset.CoreV1().Services(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -79,5 +228,6 @@ func (h *Handler) GetServiceYAMLHandler(c *gin.Context) {
 		"name":      name,
 		"namespace": namespace,
 		"yaml":      string(yamlData),
+		"ingresses": serviceIngresses(c.Request.Context(), clientset, namespace, name),
 	})
 }
@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+func (h *Handler) GetStatefulSetsHandler(c *gin.Context) {
+	namespace := c.DefaultQuery("namespace", "market")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	statefulSets, err := h.resources(c).ListStatefulSets(c.Request.Context(), namespace, liveRead(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Поды и PVC нужны для статуса по каждому ordinal
+	pods, err := h.resources(c).ListPods(c.Request.Context(), namespace, liveRead(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	podMap := make(map[string]corev1.Pod)
+	for _, pod := range pods.Items {
+		podMap[pod.Namespace+"/"+pod.Name] = pod
+	}
+
+	pvcMap := make(map[string]corev1.PersistentVolumeClaim)
+	pvcs, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(c.Request.Context(), metav1.ListOptions{})
+	if err == nil {
+		for _, pvc := range pvcs.Items {
+			pvcMap[pvc.Namespace+"/"+pvc.Name] = pvc
+		}
+	}
+
+	var result []gin.H
+	for _, sts := range statefulSets.Items {
+		replicas := statefulSetReplicas(&sts)
+
+		result = append(result, gin.H{
+			"name":             sts.Name,
+			"namespace":        sts.Namespace,
+			"ready":            fmt.Sprintf("%d/%d", sts.Status.ReadyReplicas, replicas),
+			"ready_count":      sts.Status.ReadyReplicas,
+			"total_count":      replicas,
+			"replicas":         replicas,
+			"updated_replicas": sts.Status.UpdatedReplicas,
+			"current_revision": sts.Status.CurrentRevision,
+			"update_revision":  sts.Status.UpdateRevision,
+			"service_name":     sts.Spec.ServiceName,
+			"strategy":         string(sts.Spec.UpdateStrategy.Type),
+			"partition":        statefulSetPartition(&sts),
+			"age":              time.Since(sts.CreationTimestamp.Time).Round(time.Second).String(),
+			"labels":           sts.Labels,
+			"ordinals":         statefulSetOrdinals(&sts, podMap, pvcMap),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"namespace":    namespace,
+		"count":        len(statefulSets.Items),
+		"statefulsets": result,
+		"source":       readSource(c),
+	})
+}
+
+// statefulSetOrdinals - статус пода и привязанные PVC для каждой реплики
+func statefulSetOrdinals(sts *appsv1.StatefulSet, podMap map[string]corev1.Pod, pvcMap map[string]corev1.PersistentVolumeClaim) []gin.H {
+	start := int32(0)
+	if sts.Spec.Ordinals != nil {
+		start = sts.Spec.Ordinals.Start
+	}
+
+	ordinals := []gin.H{}
+	for i := start; i < start+statefulSetReplicas(sts); i++ {
+		podName := fmt.Sprintf("%s-%d", sts.Name, i)
+
+		podStatus := gin.H{
+			"name":   podName,
+			"exists": false,
+		}
+		if pod, exists := podMap[sts.Namespace+"/"+podName]; exists {
+			ready := 0
+			for _, status := range pod.Status.ContainerStatuses {
+				if status.Ready {
+					ready++
+				}
+			}
+
+			podStatus = gin.H{
+				"name":     podName,
+				"exists":   true,
+				"status":   pod.Status.Phase,
+				"ready":    fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers)),
+				"restarts": getRestartCount(pod),
+				"revision": pod.Labels[appsv1.StatefulSetRevisionLabel],
+				"node":     pod.Spec.NodeName,
+				"ip":       pod.Status.PodIP,
+			}
+		}
+
+		volumes := []gin.H{}
+		for _, template := range sts.Spec.VolumeClaimTemplates {
+			pvcName := fmt.Sprintf("%s-%s-%d", template.Name, sts.Name, i)
+
+			volume := gin.H{
+				"template": template.Name,
+				"pvc":      pvcName,
+				"status":   "Missing",
+			}
+			if pvc, exists := pvcMap[sts.Namespace+"/"+pvcName]; exists {
+				volume["status"] = string(pvc.Status.Phase)
+				volume["volume"] = pvc.Spec.VolumeName
+				volume["storageClass"] = pvc.Spec.StorageClassName
+				if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+					volume["capacity"] = capacity.String()
+				}
+			}
+			volumes = append(volumes, volume)
+		}
+
+		ordinals = append(ordinals, gin.H{
+			"ordinal": i,
+			"pod":     podStatus,
+			"volumes": volumes,
+		})
+	}
+
+	return ordinals
+}
+
+func (h *Handler) GetStatefulSetYAMLHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	sts, err := clientset.AppsV1().StatefulSets(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Конвертируем в YAML
+	sts.ManagedFields = nil
+	sts.TypeMeta = metav1.TypeMeta{
+		APIVersion: "apps/v1",
+		Kind:       "StatefulSet",
+	}
+
+	yamlData, err := sigsyaml.Marshal(sts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"name":      name,
+		"namespace": namespace,
+		"yaml":      string(yamlData),
+	})
+}
+
+func (h *Handler) UpdateStatefulSetYAMLHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	// Server-side apply: неизвестные поля сохраняются, resourceVersion не проверяется.
+	// ?dryRun=All - только валидация, ?force=true - забрать поля у других менеджеров
+	response := h.applyEditedYAML(c, schema.GroupKind{Group: "apps", Kind: "StatefulSet"}, namespace, name)
+	if response == nil {
+		return
+	}
+
+	response["name"] = name
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) ScaleStatefulSetHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	replicasStr := c.DefaultQuery("replicas", "1")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	replicas, err := strconv.Atoi(replicasStr)
+	if err != nil || replicas < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid replicas value"})
+		return
+	}
+
+	sts, err := clientset.AppsV1().StatefulSets(namespace).Get(
+		c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "StatefulSet not found: " + err.Error()})
+		return
+	}
+
+	sts.Spec.Replicas = int32Ptr(int32(replicas))
+	_, err = clientset.AppsV1().StatefulSets(namespace).Update(
+		c.Request.Context(), sts, metav1.UpdateOptions{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     fmt.Sprintf("StatefulSet %s scaled to %d replicas", name, replicas),
+		"statefulset": name,
+		"replicas":    replicas,
+		"namespace":   namespace,
+	})
+}
+
+func (h *Handler) RestartStatefulSetHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	sts, err := clientset.AppsV1().StatefulSets(namespace).Get(
+		c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "StatefulSet not found"})
+		return
+	}
+
+	// Добавляем аннотацию для рестарта
+	if sts.Spec.Template.ObjectMeta.Annotations == nil {
+		sts.Spec.Template.ObjectMeta.Annotations = make(map[string]string)
+	}
+	sts.Spec.Template.ObjectMeta.Annotations["kubectl.kubernetes.io/restartedAt"] =
+		time.Now().Format(time.RFC3339)
+
+	_, err = clientset.AppsV1().StatefulSets(namespace).Update(
+		c.Request.Context(), sts, metav1.UpdateOptions{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     fmt.Sprintf("StatefulSet %s restarted", name),
+		"statefulset": name,
+		"partition":   statefulSetPartition(sts),
+		"time":        time.Now().Format(time.RFC3339),
+	})
+}
+
+// UpdateStatefulSetPartitionHandler - управление partition для RollingUpdate.
+// Обновляются только поды с ordinal >= partition, что позволяет катить canary.
+func (h *Handler) UpdateStatefulSetPartitionHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	partition, err := strconv.Atoi(c.Query("partition"))
+	if err != nil || partition < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid partition value"})
+		return
+	}
+
+	sts, err := clientset.AppsV1().StatefulSets(namespace).Get(
+		c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "StatefulSet not found: " + err.Error()})
+		return
+	}
+
+	if sts.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Partition is only supported with the RollingUpdate strategy",
+		})
+		return
+	}
+
+	sts.Spec.UpdateStrategy.Type = appsv1.RollingUpdateStatefulSetStrategyType
+	if sts.Spec.UpdateStrategy.RollingUpdate == nil {
+		sts.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{}
+	}
+	sts.Spec.UpdateStrategy.RollingUpdate.Partition = int32Ptr(int32(partition))
+
+	_, err = clientset.AppsV1().StatefulSets(namespace).Update(
+		c.Request.Context(), sts, metav1.UpdateOptions{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     fmt.Sprintf("StatefulSet %s partition set to %d", name, partition),
+		"statefulset": name,
+		"namespace":   namespace,
+		"partition":   partition,
+		"replicas":    statefulSetReplicas(sts),
+	})
+}
+
+func (h *Handler) DeleteStatefulSetHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	clientset := h.clientset(c)
+	if clientset == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	err := clientset.AppsV1().StatefulSets(namespace).Delete(
+		c.Request.Context(), name, metav1.DeleteOptions{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "StatefulSet deleted successfully",
+		"statefulset": name,
+		"namespace":   namespace,
+	})
+}
+
+func statefulSetReplicas(sts *appsv1.StatefulSet) int32 {
+	if sts.Spec.Replicas != nil {
+		return *sts.Spec.Replicas
+	}
+	return 1
+}
+
+func statefulSetPartition(sts *appsv1.StatefulSet) int32 {
+	if sts.Spec.UpdateStrategy.RollingUpdate != nil && sts.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
+		return *sts.Spec.UpdateStrategy.RollingUpdate.Partition
+	}
+	return 0
+}
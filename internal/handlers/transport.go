@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// Параметры Server-Sent Events
+const (
+	sseHeartbeatInterval = 15 * time.Second // комментарий-heartbeat, чтобы прокси не рвали простаивающее соединение
+	sseRetry             = 3000             // мс до переподключения EventSource
+)
+
+// eventSink - клиент потока событий: WebSocket или Server-Sent Events.
+// Send и Ping вызываются из одной горутины.
+type eventSink interface {
+	// Send - отправка JSON-сообщения; id попадает в поле id: SSE (Last-Event-ID при переподключении)
+	Send(id string, v interface{}) error
+	// Ping - keepalive: ping-фрейм WebSocket или комментарий SSE
+	Ping() error
+	PingInterval() time.Duration
+	// Closed - закрывается, когда клиент отключился
+	Closed() <-chan struct{}
+	Close()
+}
+
+// wantsSSE - клиент ждет text/event-stream (EventSource, curl -H 'Accept: text/event-stream' или ?transport=sse)
+func wantsSSE(c *gin.Context) bool {
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		return false
+	}
+	return c.Query("transport") == "sse" || strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+}
+
+// lastEventID - точка возобновления: заголовок Last-Event-ID (EventSource) или ?lastEventId=
+func lastEventID(c *gin.Context) string {
+	if id := c.GetHeader("Last-Event-ID"); id != "" {
+		return id
+	}
+	return c.Query("lastEventId")
+}
+
+// openEventSink - SSE или WebSocket в зависимости от запроса.
+// При ошибке upgrade ответ клиенту уже записан.
+func openEventSink(c *gin.Context) (eventSink, error) {
+	if wantsSSE(c) {
+		return newSSESink(c), nil
+	}
+
+	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newWSSink(ws), nil
+}
+
+// wsSink - WebSocket с ping/pong; входящие сообщения читаются только ради pong и закрытия
+type wsSink struct {
+	conn   *websocket.Conn
+	closed chan struct{}
+}
+
+func newWSSink(conn *websocket.Conn) *wsSink {
+	sink := &wsSink{conn: conn, closed: make(chan struct{})}
+
+	conn.SetReadLimit(4096)
+	conn.SetReadDeadline(time.Now().Add(logPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(logPongWait))
+	})
+	go func() {
+		defer close(sink.closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	return sink
+}
+
+func (s *wsSink) Send(id string, v interface{}) error {
+	s.conn.SetWriteDeadline(time.Now().Add(logWriteWait))
+	return s.conn.WriteJSON(v)
+}
+
+func (s *wsSink) Ping() error {
+	return s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(logWriteWait))
+}
+
+func (s *wsSink) PingInterval() time.Duration { return logPingInterval }
+func (s *wsSink) Closed() <-chan struct{}     { return s.closed }
+func (s *wsSink) Close()                      { s.conn.Close() }
+
+// sseSink - text/event-stream: каждое сообщение - событие с JSON в data:
+type sseSink struct {
+	w      gin.ResponseWriter
+	closed <-chan struct{}
+}
+
+func newSSESink(c *gin.Context) *sseSink {
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // nginx не должен буферизовать поток
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", sseRetry)
+	c.Writer.Flush()
+
+	return &sseSink{w: c.Writer, closed: c.Request.Context().Done()}
+}
+
+func (s *sseSink) Send(id string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if _, err := fmt.Fprintf(s.w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(s.w, "data: %s\n\n", data); err != nil {
+		return err
+	}
+	s.w.Flush()
+	return nil
+}
+
+func (s *sseSink) Ping() error {
+	if _, err := fmt.Fprintf(s.w, ": heartbeat %s\n\n", time.Now().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	s.w.Flush()
+	return nil
+}
+
+func (s *sseSink) PingInterval() time.Duration { return sseHeartbeatInterval }
+func (s *sseSink) Closed() <-chan struct{}     { return s.closed }
+func (s *sseSink) Close()                      {}
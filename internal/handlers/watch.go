@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"k8s-manager/internal/k8s"
+)
+
+// GetWatchFeedsHandler - поддерживаемые ресурсы и активные информеры хаба
+func (h *Handler) GetWatchFeedsHandler(c *gin.Context) {
+	cluster := h.cluster(c)
+	if cluster == nil || cluster.Watches == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"resources": k8s.WatchResources(),
+		"feeds":     cluster.Watches.Feeds(),
+	})
+}
+
+// WatchResourceHandler - изменения ресурса в реальном времени через WebSocket или SSE.
+// ?namespace=default|all&labelSelector=&fieldSelector=
+// Сначала приходят ADDED для текущих объектов и сообщение synced, затем MODIFIED (только изменившиеся поля)
+// и DELETED. После переподключения снова приходит полный снимок.
+func (h *Handler) WatchResourceHandler(c *gin.Context) {
+	resource := c.Param("resource")
+
+	namespace := c.DefaultQuery("namespace", "default")
+	if namespace == "all" {
+		namespace = ""
+	}
+
+	labelSelector, err := labels.Parse(c.Query("labelSelector"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid labelSelector: " + err.Error()})
+		return
+	}
+	fieldSelector, err := fields.ParseSelector(c.Query("fieldSelector"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fieldSelector: " + err.Error()})
+		return
+	}
+
+	cluster := h.cluster(c)
+	if cluster == nil || cluster.Watches == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K8s client not ready"})
+		return
+	}
+
+	sub, err := cluster.Watches.Subscribe(resource, namespace, labelSelector, fieldSelector)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer sub.Close()
+
+	sink, err := openEventSink(c)
+	if err != nil {
+		log.Printf("WebSocket upgrade error: %v", err)
+		return
+	}
+	defer sink.Close()
+
+	scope := sub.Namespace()
+	if scope == "" {
+		scope = "all namespaces"
+	}
+	sink.Send("", LogMessage{
+		Type:    "info",
+		Message: fmt.Sprintf("Started watching %s in %s", resource, scope),
+		Data: gin.H{
+			"resource":      resource,
+			"namespace":     sub.Namespace(),
+			"labelSelector": labelSelector.String(),
+			"fieldSelector": fieldSelector.String(),
+		},
+		Time: time.Now().Format(time.RFC3339),
+	})
+
+	pingTicker := time.NewTicker(sink.PingInterval())
+	defer pingTicker.Stop()
+
+	send := func() bool {
+		for _, event := range sub.Drain() {
+			if err := sink.Send(event.ResourceVersion, event); err != nil {
+				return false
+			}
+		}
+		return true
+	}
+
+	synced := sub.Synced()
+	for {
+		select {
+		case <-sink.Closed():
+			return
+
+		case <-pingTicker.C:
+			if err := sink.Ping(); err != nil {
+				return
+			}
+
+		case <-sub.Ready():
+			if !send() {
+				return
+			}
+
+		case <-synced:
+			// Начальный снимок отправляем до отметки о синхронизации
+			synced = nil
+			if !send() {
+				return
+			}
+			if err := sink.Send("", LogMessage{
+				Type:    "synced",
+				Message: fmt.Sprintf("Initial %s state sent", resource),
+				Time:    time.Now().Format(time.RFC3339),
+			}); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// FieldManager - имя менеджера полей k8s-manager при server-side apply
+const FieldManager = "k8s-manager"
+
+// LastAppliedAnnotation - аннотация client-side kubectl apply, при сравнении это шум
+const LastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// Результат применения объекта (как в kubectl apply)
+const (
+	ApplyCreated    = "created"
+	ApplyConfigured = "configured"
+	ApplyUnchanged  = "unchanged"
+	ApplyFailed     = "error"
+)
+
+// ApplyOptions - параметры server-side apply
+type ApplyOptions struct {
+	DryRun bool // dryRun=All: сервер валидирует и возвращает результат, ничего не сохраняя
+	Force  bool // забрать поля у других менеджеров вместо конфликта
+}
+
+// ApplyResult - объект после apply и что с ним произошло
+type ApplyResult struct {
+	Object   *unstructured.Unstructured
+	Live     *unstructured.Unstructured // объект до apply; nil, если его не было
+	Resource schema.GroupVersionResource
+	Action   string
+}
+
+// ApplyConflict - поле, которым уже владеет другой менеджер
+type ApplyConflict struct {
+	Field   string `json:"field"`
+	Manager string `json:"manager"`
+	Message string `json:"message"`
+}
+
+var conflictManagerRe = regexp.MustCompile(`conflict with "([^"]+)"`)
+
+// DecodeObject - один объект из YAML или JSON
+func DecodeObject(data []byte) (*unstructured.Unstructured, error) {
+	jsonData, err := sigsyaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(jsonData); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// StripServerFields - убирает поля, которые заполняет сервер: status, managedFields,
+// resourceVersion и т.п. Без resourceVersion apply не падает на устаревшей версии.
+func StripServerFields(obj *unstructured.Unstructured) {
+	delete(obj.Object, "status")
+	for _, field := range []string{"managedFields", "resourceVersion", "uid", "generation", "creationTimestamp", "selfLink"} {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+}
+
+// DiffView - копия объекта для сравнения: без серверных полей и last-applied-configuration
+func DiffView(obj *unstructured.Unstructured) map[string]interface{} {
+	if obj == nil {
+		return map[string]interface{}{}
+	}
+
+	clean := obj.DeepCopy()
+	StripServerFields(clean)
+	unstructured.RemoveNestedField(clean.Object, "metadata", "annotations", LastAppliedAnnotation)
+	if annotations, found, _ := unstructured.NestedMap(clean.Object, "metadata", "annotations"); found && len(annotations) == 0 {
+		unstructured.RemoveNestedField(clean.Object, "metadata", "annotations")
+	}
+	return clean.Object
+}
+
+// RESTMapping - ресурс API для GVK. При промахе кеш discovery сбрасывается,
+// чтобы найти только что созданные CRD.
+func (c *Cluster) RESTMapping(gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	mapping, err := c.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		c.Mapper.Reset()
+		mapping, err = c.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	return mapping, err
+}
+
+// ResourceClient - клиент ресурса с учетом области (namespace или кластер)
+func (c *Cluster) ResourceClient(mapping *meta.RESTMapping, namespace string) dynamic.ResourceInterface {
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		return c.Dynamic.Resource(mapping.Resource).Namespace(namespace)
+	}
+	return c.Dynamic.Resource(mapping.Resource)
+}
+
+// Apply - server-side apply объекта от имени FieldManager.
+// Для namespaced объектов без namespace используется defaultNamespace.
+func (c *Cluster) Apply(ctx context.Context, obj *unstructured.Unstructured, defaultNamespace string, opts ApplyOptions) (*ApplyResult, error) {
+	gvk := obj.GroupVersionKind()
+	if gvk.Kind == "" || gvk.Version == "" {
+		return nil, fmt.Errorf("apiVersion and kind are required")
+	}
+	if obj.GetName() == "" {
+		return nil, fmt.Errorf("%s: metadata.name is required", gvk.Kind)
+	}
+
+	mapping, err := c.RESTMapping(gvk)
+	if err != nil {
+		return nil, err
+	}
+
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(defaultNamespace)
+		}
+	} else {
+		obj.SetNamespace("")
+	}
+	StripServerFields(obj)
+
+	client := c.ResourceClient(mapping, obj.GetNamespace())
+
+	live, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		live = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	applyOptions := metav1.ApplyOptions{FieldManager: FieldManager, Force: opts.Force}
+	if opts.DryRun {
+		applyOptions.DryRun = []string{metav1.DryRunAll}
+	}
+
+	applied, err := client.Apply(ctx, obj.GetName(), obj, applyOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ApplyResult{Object: applied, Live: live, Resource: mapping.Resource, Action: ApplyConfigured}
+	switch {
+	case live == nil:
+		result.Action = ApplyCreated
+	case sameObject(live, applied):
+		result.Action = ApplyUnchanged
+	}
+	return result, nil
+}
+
+// sameObject - объекты совпадают без учета managedFields, resourceVersion и generation
+// (apply нового менеджера меняет managedFields, даже если значения те же)
+func sameObject(a, b *unstructured.Unstructured) bool {
+	a, b = a.DeepCopy(), b.DeepCopy()
+	for _, obj := range []*unstructured.Unstructured{a, b} {
+		for _, field := range []string{"managedFields", "resourceVersion", "generation"} {
+			unstructured.RemoveNestedField(obj.Object, "metadata", field)
+		}
+	}
+	return reflect.DeepEqual(a.Object, b.Object)
+}
+
+// ApplyConflicts - конфликты владения полями из ошибки apply (409); nil для других ошибок
+func ApplyConflicts(err error) []ApplyConflict {
+	var status apierrors.APIStatus
+	if !apierrors.IsConflict(err) || !errors.As(err, &status) {
+		return nil
+	}
+
+	details := status.Status().Details
+	if details == nil {
+		return nil
+	}
+
+	var conflicts []ApplyConflict
+	for _, cause := range details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		conflict := ApplyConflict{Field: cause.Field, Message: cause.Message}
+		if match := conflictManagerRe.FindStringSubmatch(cause.Message); match != nil {
+			conflict.Manager = match[1]
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
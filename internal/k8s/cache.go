@@ -0,0 +1,439 @@
+package k8s
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// cacheResync - период полной пересинхронизации информеров
+	cacheResync = 10 * time.Minute
+	// cacheSyncTimeout - через сколько после старта сообщить о несинхронизированных информерах
+	cacheSyncTimeout = 10 * time.Second
+)
+
+// ResourceCache - кэш ресурсов кластера на базе SharedInformerFactory.
+// Пока информер не синхронизирован, чтение идет напрямую из API.
+type ResourceCache struct {
+	clientset *kubernetes.Clientset
+	factory   informers.SharedInformerFactory
+	informers map[string]cache.SharedIndexInformer
+
+	pods         corelisters.PodLister
+	services     corelisters.ServiceLister
+	slices       discoverylisters.EndpointSliceLister
+	nodes        corelisters.NodeLister
+	namespaces   corelisters.NamespaceLister
+	deployments  appslisters.DeploymentLister
+	statefulSets appslisters.StatefulSetLister
+	daemonSets   appslisters.DaemonSetLister
+	replicaSets  appslisters.ReplicaSetLister
+	jobs         batchlisters.JobLister
+	cronJobs     batchlisters.CronJobLister
+
+	startOnce sync.Once
+	started   bool
+	mu        sync.RWMutex
+	stopChan  chan struct{}
+}
+
+// NewResourceCache - создает кэш, информеры запускаются при первом обращении к кластеру
+func NewResourceCache(clientset *kubernetes.Clientset) *ResourceCache {
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, cacheResync,
+		informers.WithTransform(stripManagedFields))
+
+	rc := &ResourceCache{
+		clientset: clientset,
+		factory:   factory,
+		informers: make(map[string]cache.SharedIndexInformer),
+		stopChan:  make(chan struct{}),
+	}
+
+	podInformer := factory.Core().V1().Pods()
+	serviceInformer := factory.Core().V1().Services()
+	sliceInformer := factory.Discovery().V1().EndpointSlices()
+	nodeInformer := factory.Core().V1().Nodes()
+	namespaceInformer := factory.Core().V1().Namespaces()
+	deploymentInformer := factory.Apps().V1().Deployments()
+	statefulSetInformer := factory.Apps().V1().StatefulSets()
+	daemonSetInformer := factory.Apps().V1().DaemonSets()
+	replicaSetInformer := factory.Apps().V1().ReplicaSets()
+	jobInformer := factory.Batch().V1().Jobs()
+	cronJobInformer := factory.Batch().V1().CronJobs()
+
+	rc.informers["pods"] = podInformer.Informer()
+	rc.informers["services"] = serviceInformer.Informer()
+	rc.informers["endpointslices"] = sliceInformer.Informer()
+	rc.informers["nodes"] = nodeInformer.Informer()
+	rc.informers["namespaces"] = namespaceInformer.Informer()
+	rc.informers["deployments"] = deploymentInformer.Informer()
+	rc.informers["statefulsets"] = statefulSetInformer.Informer()
+	rc.informers["daemonsets"] = daemonSetInformer.Informer()
+	rc.informers["replicasets"] = replicaSetInformer.Informer()
+	rc.informers["jobs"] = jobInformer.Informer()
+	rc.informers["cronjobs"] = cronJobInformer.Informer()
+
+	rc.pods = podInformer.Lister()
+	rc.services = serviceInformer.Lister()
+	rc.slices = sliceInformer.Lister()
+	rc.nodes = nodeInformer.Lister()
+	rc.namespaces = namespaceInformer.Lister()
+	rc.deployments = deploymentInformer.Lister()
+	rc.statefulSets = statefulSetInformer.Lister()
+	rc.daemonSets = daemonSetInformer.Lister()
+	rc.replicaSets = replicaSetInformer.Lister()
+	rc.jobs = jobInformer.Lister()
+	rc.cronJobs = cronJobInformer.Lister()
+
+	return rc
+}
+
+// stripManagedFields - убирает managedFields, чтобы не держать их в памяти
+func stripManagedFields(obj interface{}) (interface{}, error) {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+	}
+	return obj, nil
+}
+
+// Start - запускает информеры (однократно) и не ждет синхронизации: первичная загрузка идет в фоне,
+// до ее окончания запросы читают из API
+func (rc *ResourceCache) Start() {
+	rc.startOnce.Do(func() {
+		log.Println("🗄️  Starting shared informer cache...")
+		rc.factory.Start(rc.stopChan)
+
+		rc.mu.Lock()
+		rc.started = true
+		rc.mu.Unlock()
+
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), cacheSyncTimeout)
+			defer cancel()
+
+			for resource, synced := range rc.factory.WaitForCacheSync(ctx.Done()) {
+				if !synced {
+					log.Printf("⚠️  Informer cache for %v not synced yet, falling back to live reads", resource)
+				}
+			}
+		}()
+	})
+}
+
+// SyncStatus - признак синхронизации по каждому ресурсу
+func (rc *ResourceCache) SyncStatus() map[string]bool {
+	rc.mu.RLock()
+	started := rc.started
+	rc.mu.RUnlock()
+
+	status := make(map[string]bool, len(rc.informers))
+	for resource, informer := range rc.informers {
+		status[resource] = started && informer.HasSynced()
+	}
+	return status
+}
+
+// Started - запущен ли кэш
+func (rc *ResourceCache) Started() bool {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.started
+}
+
+// sortObjects - порядок LIST из API (namespace, затем имя); lister отдает объекты в порядке обхода map
+func sortObjects[T metav1.Object](items []T) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].GetNamespace() != items[j].GetNamespace() {
+			return items[i].GetNamespace() < items[j].GetNamespace()
+		}
+		return items[i].GetName() < items[j].GetName()
+	})
+}
+
+// readSourceKey - ключ ReadSource в context запроса
+type readSourceKey struct{}
+
+// ReadSource - откуда запрос фактически читал: из кэша или из API (live=true или кэш еще не синхронизирован)
+type ReadSource struct {
+	live atomic.Bool
+}
+
+// WithReadSource - context, в котором методы ResourceCache отмечают чтение из API
+func WithReadSource(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readSourceKey{}, &ReadSource{})
+}
+
+// ReadSourceFrom - ReadSource из context; nil, если его нет
+func ReadSourceFrom(ctx context.Context) *ReadSource {
+	source, _ := ctx.Value(readSourceKey{}).(*ReadSource)
+	return source
+}
+
+// String - "live", если хотя бы одно чтение ушло в API, иначе "cache"
+func (s *ReadSource) String() string {
+	if s.live.Load() {
+		return "live"
+	}
+	return "cache"
+}
+
+// sharedInformer - запущенный информер кэша для ресурса или nil, если кэш ресурс не держит
+func (rc *ResourceCache) sharedInformer(resource string) cache.SharedIndexInformer {
+	informer, ok := rc.informers[resource]
+	if !ok {
+		return nil
+	}
+	rc.Start()
+	return informer
+}
+
+// useCache - можно ли отвечать из кэша для ресурса. Первое обращение запускает информеры в фоне;
+// пока они не синхронизированы, чтение идет из API (и отмечается в ReadSource запроса)
+func (rc *ResourceCache) useCache(ctx context.Context, resource string, live bool) bool {
+	if !live {
+		rc.Start()
+		if rc.informers[resource].HasSynced() {
+			return true
+		}
+	}
+	if source := ReadSourceFrom(ctx); source != nil {
+		source.live.Store(true)
+	}
+	return false
+}
+
+// ListPods - список подов namespace ("" - все namespace)
+func (rc *ResourceCache) ListPods(ctx context.Context, namespace string, live bool) (*corev1.PodList, error) {
+	if !rc.useCache(ctx, "pods", live) {
+		return rc.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	}
+
+	items, err := rc.pods.Pods(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sortObjects(items)
+
+	list := &corev1.PodList{}
+	for _, item := range items {
+		list.Items = append(list.Items, *item)
+	}
+	return list, nil
+}
+
+// GetPod - под по имени
+func (rc *ResourceCache) GetPod(ctx context.Context, namespace, name string, live bool) (*corev1.Pod, error) {
+	if !rc.useCache(ctx, "pods", live) {
+		return rc.clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+	return rc.pods.Pods(namespace).Get(name)
+}
+
+// ListDeployments - список деплойментов namespace ("" - все namespace)
+func (rc *ResourceCache) ListDeployments(ctx context.Context, namespace string, live bool) (*appsv1.DeploymentList, error) {
+	if !rc.useCache(ctx, "deployments", live) {
+		return rc.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	}
+
+	items, err := rc.deployments.Deployments(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sortObjects(items)
+
+	list := &appsv1.DeploymentList{}
+	for _, item := range items {
+		list.Items = append(list.Items, *item)
+	}
+	return list, nil
+}
+
+// ListStatefulSets - список StatefulSet namespace ("" - все namespace)
+func (rc *ResourceCache) ListStatefulSets(ctx context.Context, namespace string, live bool) (*appsv1.StatefulSetList, error) {
+	if !rc.useCache(ctx, "statefulsets", live) {
+		return rc.clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	}
+
+	items, err := rc.statefulSets.StatefulSets(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sortObjects(items)
+
+	list := &appsv1.StatefulSetList{}
+	for _, item := range items {
+		list.Items = append(list.Items, *item)
+	}
+	return list, nil
+}
+
+// ListDaemonSets - список DaemonSet namespace ("" - все namespace)
+func (rc *ResourceCache) ListDaemonSets(ctx context.Context, namespace string, live bool) (*appsv1.DaemonSetList, error) {
+	if !rc.useCache(ctx, "daemonsets", live) {
+		return rc.clientset.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	}
+
+	items, err := rc.daemonSets.DaemonSets(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sortObjects(items)
+
+	list := &appsv1.DaemonSetList{}
+	for _, item := range items {
+		list.Items = append(list.Items, *item)
+	}
+	return list, nil
+}
+
+// ListReplicaSets - список ReplicaSet namespace ("" - все namespace)
+func (rc *ResourceCache) ListReplicaSets(ctx context.Context, namespace string, live bool) (*appsv1.ReplicaSetList, error) {
+	if !rc.useCache(ctx, "replicasets", live) {
+		return rc.clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	}
+
+	items, err := rc.replicaSets.ReplicaSets(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sortObjects(items)
+
+	list := &appsv1.ReplicaSetList{}
+	for _, item := range items {
+		list.Items = append(list.Items, *item)
+	}
+	return list, nil
+}
+
+// ListJobs - список Job namespace ("" - все namespace)
+func (rc *ResourceCache) ListJobs(ctx context.Context, namespace string, live bool) (*batchv1.JobList, error) {
+	if !rc.useCache(ctx, "jobs", live) {
+		return rc.clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	}
+
+	items, err := rc.jobs.Jobs(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sortObjects(items)
+
+	list := &batchv1.JobList{}
+	for _, item := range items {
+		list.Items = append(list.Items, *item)
+	}
+	return list, nil
+}
+
+// ListCronJobs - список CronJob namespace ("" - все namespace)
+func (rc *ResourceCache) ListCronJobs(ctx context.Context, namespace string, live bool) (*batchv1.CronJobList, error) {
+	if !rc.useCache(ctx, "cronjobs", live) {
+		return rc.clientset.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	}
+
+	items, err := rc.cronJobs.CronJobs(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sortObjects(items)
+
+	list := &batchv1.CronJobList{}
+	for _, item := range items {
+		list.Items = append(list.Items, *item)
+	}
+	return list, nil
+}
+
+// ListServices - список сервисов namespace ("" - все namespace)
+func (rc *ResourceCache) ListServices(ctx context.Context, namespace string, live bool) (*corev1.ServiceList, error) {
+	if !rc.useCache(ctx, "services", live) {
+		return rc.clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	}
+
+	items, err := rc.services.Services(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sortObjects(items)
+
+	list := &corev1.ServiceList{}
+	for _, item := range items {
+		list.Items = append(list.Items, *item)
+	}
+	return list, nil
+}
+
+// ListEndpointSlices - EndpointSlice namespace ("" - все namespace)
+func (rc *ResourceCache) ListEndpointSlices(ctx context.Context, namespace string, live bool) (*discoveryv1.EndpointSliceList, error) {
+	if !rc.useCache(ctx, "endpointslices", live) {
+		return rc.clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{})
+	}
+
+	items, err := rc.slices.EndpointSlices(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sortObjects(items)
+
+	list := &discoveryv1.EndpointSliceList{}
+	for _, item := range items {
+		list.Items = append(list.Items, *item)
+	}
+	return list, nil
+}
+
+// ListNodes - список нод
+func (rc *ResourceCache) ListNodes(ctx context.Context, live bool) (*corev1.NodeList, error) {
+	if !rc.useCache(ctx, "nodes", live) {
+		return rc.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	}
+
+	items, err := rc.nodes.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sortObjects(items)
+
+	list := &corev1.NodeList{}
+	for _, item := range items {
+		list.Items = append(list.Items, *item)
+	}
+	return list, nil
+}
+
+// ListNamespaces - список namespace
+func (rc *ResourceCache) ListNamespaces(ctx context.Context, live bool) (*corev1.NamespaceList, error) {
+	if !rc.useCache(ctx, "namespaces", live) {
+		return rc.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	}
+
+	items, err := rc.namespaces.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sortObjects(items)
+
+	list := &corev1.NamespaceList{}
+	for _, item := range items {
+		list.Items = append(list.Items, *item)
+	}
+	return list, nil
+}
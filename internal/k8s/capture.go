@@ -0,0 +1,548 @@
+package k8s
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+// Настройки захвата логов (переменные окружения)
+const (
+	defaultCaptureDir       = "captures"
+	defaultCaptureMaxSizeMB = 10
+	defaultCaptureMaxFiles  = 10
+)
+
+// CaptureConfig - куда и как хранить захваченные логи
+type CaptureConfig struct {
+	Dir      string // LOG_CAPTURE_DIR
+	MaxSize  int64  // LOG_CAPTURE_MAX_SIZE_MB - размер файла до ротации
+	MaxFiles int    // LOG_CAPTURE_MAX_FILES - сжатых архивов на контейнер
+}
+
+// LoadCaptureConfig - настройки из окружения с значениями по умолчанию
+func LoadCaptureConfig() CaptureConfig {
+	cfg := CaptureConfig{
+		Dir:      os.Getenv("LOG_CAPTURE_DIR"),
+		MaxSize:  defaultCaptureMaxSizeMB * 1024 * 1024,
+		MaxFiles: defaultCaptureMaxFiles,
+	}
+	if cfg.Dir == "" {
+		cfg.Dir = defaultCaptureDir
+	}
+	if mb, err := strconv.Atoi(os.Getenv("LOG_CAPTURE_MAX_SIZE_MB")); err == nil && mb > 0 {
+		cfg.MaxSize = int64(mb) * 1024 * 1024
+	}
+	if n, err := strconv.Atoi(os.Getenv("LOG_CAPTURE_MAX_FILES")); err == nil && n > 0 {
+		cfg.MaxFiles = n
+	}
+	return cfg
+}
+
+type CaptureSession struct {
+	ID        string    `json:"id"`
+	Cluster   string    `json:"cluster"`
+	Namespace string    `json:"namespace"`
+	Selector  string    `json:"selector"`
+	Container string    `json:"container,omitempty"` // regex по имени контейнера
+	Dir       string    `json:"dir"`
+	Status    string    `json:"status"` // running, stopped, error
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	StopChan  chan struct{}
+
+	lines     atomic.Int64
+	bytes     atomic.Int64
+	stopOnce  sync.Once
+	rotations atomic.Int64
+	mu        sync.RWMutex // Status и Error пишет горутина захвата
+}
+
+// CaptureState - статус сессии и последняя ошибка
+type CaptureState struct {
+	Status string
+	Error  string
+}
+
+// State - снимок статуса сессии
+func (s *CaptureSession) State() CaptureState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return CaptureState{Status: s.Status, Error: s.Error}
+}
+
+func (s *CaptureSession) setStatus(status string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Status = status
+	if err != nil {
+		s.Error = err.Error()
+	}
+}
+
+// Lines, Bytes, Rotations - счетчики записанного
+func (s *CaptureSession) Lines() int64     { return s.lines.Load() }
+func (s *CaptureSession) Bytes() int64     { return s.bytes.Load() }
+func (s *CaptureSession) Rotations() int64 { return s.rotations.Load() }
+
+// Stop - останавливает захват; повторный вызов безопасен
+func (s *CaptureSession) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.StopChan)
+	})
+}
+
+type CaptureManager struct {
+	sessions map[string]*CaptureSession
+	mu       sync.RWMutex
+	config   CaptureConfig
+}
+
+var captureManager = &CaptureManager{
+	sessions: make(map[string]*CaptureSession),
+	config:   LoadCaptureConfig(),
+}
+
+func GetCaptureManager() *CaptureManager {
+	return captureManager
+}
+
+// Config - настройки хранения
+func (m *CaptureManager) Config() CaptureConfig {
+	return m.config
+}
+
+func (m *CaptureManager) GetSessions() []*CaptureSession {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	sessions := make([]*CaptureSession, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+func (m *CaptureManager) AddSession(session *CaptureSession) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID] = session
+}
+
+func (m *CaptureManager) RemoveSession(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+}
+
+func (m *CaptureManager) GetSession(id string) (*CaptureSession, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	session, exists := m.sessions[id]
+	return session, exists
+}
+
+func (m *CaptureManager) StopSession(id string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	session, exists := m.sessions[id]
+	if !exists {
+		return false
+	}
+
+	session.Stop()
+	return true
+}
+
+// DeleteSession - останавливает захват (если идет) и убирает сессию из списка; файлы остаются на диске
+func (m *CaptureManager) DeleteSession(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, exists := m.sessions[id]
+	if !exists {
+		return false
+	}
+
+	session.Stop()
+	delete(m.sessions, id)
+	return true
+}
+
+// unsafePathChars - символы, недопустимые в id сессий (они же пути в URL и имена каталогов захвата);
+// имена контекстов бывают вида arn:aws:eks:.../name
+var unsafePathChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// GenerateCaptureID - id и имя каталога сессии; случайный суффикс различает захваты, начатые в одну секунду
+func GenerateCaptureID(cluster, namespace string) string {
+	return fmt.Sprintf("%s-%s-%d-%s",
+		unsafePathChars.ReplaceAllString(cluster, "_"),
+		unsafePathChars.ReplaceAllString(namespace, "_"), time.Now().Unix(), rand.String(5))
+}
+
+// NewCaptureSession - сессия с каталогом <LOG_CAPTURE_DIR>/<id>
+func (m *CaptureManager) NewCaptureSession(cluster, namespace, selector, container string) *CaptureSession {
+	id := GenerateCaptureID(cluster, namespace)
+	return &CaptureSession{
+		ID:        id,
+		Cluster:   cluster,
+		Namespace: namespace,
+		Selector:  selector,
+		Container: container,
+		Dir:       filepath.Join(m.config.Dir, id),
+		Status:    "running",
+		CreatedAt: time.Now(),
+		StopChan:  make(chan struct{}),
+	}
+}
+
+// StartCapture - фоновый follow логов подов сессии в файлы с ротацией; работает без подключенного браузера
+func StartCapture(session *CaptureSession, follower *PodLogFollower) {
+	log.Printf("🚀 Starting log capture %s: %s/%s [%s] -> %s",
+		session.ID, session.Cluster, session.Namespace, session.Selector, session.Dir)
+
+	config := captureManager.Config()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-session.StopChan
+		cancel()
+	}()
+
+	if err := os.MkdirAll(session.Dir, 0755); err != nil {
+		log.Printf("❌ Failed to create capture dir: %v", err)
+		session.setStatus("error", err)
+		return
+	}
+
+	events := make(chan PodLogEvent, 1000)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- follower.Run(ctx, events)
+	}()
+
+	// Один файл на контейнер пода: <dir>/<pod>/<container>.log
+	files := make(map[string]*rotatingFile)
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			session.setStatus("stopped", nil)
+			log.Printf("🛑 Log capture stopped: %s", session.ID)
+			return
+
+		case err := <-errChan:
+			if err != nil {
+				log.Printf("❌ Log capture %s failed: %v", session.ID, err)
+				session.setStatus("error", err)
+			} else {
+				session.setStatus("stopped", nil)
+			}
+			return
+
+		case event := <-events:
+			key := event.Pod + "/" + event.Container
+			file, ok := files[key]
+			if !ok {
+				file = &rotatingFile{
+					dir:      filepath.Join(session.Dir, event.Pod),
+					base:     event.Container,
+					maxSize:  config.MaxSize,
+					maxFiles: config.MaxFiles,
+					rotated:  &session.rotations,
+				}
+				files[key] = file
+			}
+
+			var line string
+			switch event.Type {
+			case PodLogLine:
+				line = event.Text
+				if !event.Time.IsZero() {
+					line = event.Time.Format(time.RFC3339Nano) + " " + line
+				}
+				session.lines.Add(1)
+			case PodLogJoined:
+				line = fmt.Sprintf("%s --- capture attached to %s", time.Now().Format(time.RFC3339Nano), key)
+			case PodLogLeft:
+				line = fmt.Sprintf("%s --- container stream ended (restart or deletion)", time.Now().Format(time.RFC3339Nano))
+			case PodLogWarning:
+				line = fmt.Sprintf("%s --- capture error: %v", time.Now().Format(time.RFC3339Nano), event.Err)
+			}
+
+			n, err := file.WriteLine(line)
+			session.bytes.Add(int64(n))
+			if err != nil {
+				log.Printf("❌ Log capture %s write error: %v", session.ID, err)
+			}
+		}
+	}
+}
+
+// rotatingFile - текущий <base>.log; при превышении maxSize сжимается в <base>-<время>.log.gz
+type rotatingFile struct {
+	dir      string
+	base     string
+	maxSize  int64
+	maxFiles int
+	rotated  *atomic.Int64
+
+	file *os.File
+	size int64
+}
+
+func (f *rotatingFile) path() string {
+	return filepath.Join(f.dir, f.base+".log")
+}
+
+func (f *rotatingFile) open() error {
+	if err := os.MkdirAll(f.dir, 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// WriteLine - дописывает строку, при необходимости ротируя файл
+func (f *rotatingFile) WriteLine(line string) (int, error) {
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if f.size > 0 && f.size+int64(len(line))+1 > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := io.WriteString(f.file, line+"\n")
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate - сжимает текущий файл в архив и удаляет лишние старые архивы
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	archive := filepath.Join(f.dir, fmt.Sprintf("%s-%s.log.gz", f.base, time.Now().Format("20060102-150405.000")))
+	if err := gzipFile(f.path(), archive); err != nil {
+		return err
+	}
+	if err := os.Remove(f.path()); err != nil {
+		return err
+	}
+	if f.rotated != nil {
+		f.rotated.Add(1)
+	}
+
+	// Оставляем maxFiles самых новых архивов
+	archives, err := filepath.Glob(filepath.Join(f.dir, f.base+"-*.log.gz"))
+	if err == nil && len(archives) > f.maxFiles {
+		sort.Strings(archives) // имена содержат время - сортировка хронологическая
+		for _, old := range archives[:len(archives)-f.maxFiles] {
+			os.Remove(old)
+		}
+	}
+
+	return f.open()
+}
+
+func (f *rotatingFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func gzipFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(out)
+	gz.Name = filepath.Base(src)
+	if _, err := io.Copy(gz, in); err != nil {
+		gz.Close()
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// CaptureFile - файл захвата относительно LOG_CAPTURE_DIR
+type CaptureFile struct {
+	Path       string    `json:"path"`
+	Session    string    `json:"session"`
+	Pod        string    `json:"pod"`
+	Container  string    `json:"container"`
+	Size       int64     `json:"size"`
+	ModTime    time.Time `json:"modTime"`
+	Compressed bool      `json:"compressed"`
+}
+
+// ListCaptureFiles - файлы захвата, опционально по сессии и поду; новые первыми
+func (m *CaptureManager) ListCaptureFiles(sessionID, pod string) ([]CaptureFile, error) {
+	var files []CaptureFile
+
+	err := filepath.WalkDir(m.config.Dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(m.config.Dir, path)
+		if err != nil {
+			return err
+		}
+
+		// <session>/<pod>/<container>(-<время>).log[.gz]
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if len(parts) != 3 {
+			return nil
+		}
+		if (sessionID != "" && parts[0] != sessionID) || (pod != "" && parts[1] != pod) {
+			return nil
+		}
+
+		name := parts[2]
+		compressed := strings.HasSuffix(name, ".log.gz")
+		if !compressed && !strings.HasSuffix(name, ".log") {
+			return nil
+		}
+
+		container := strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ".log")
+		if compressed {
+			// Отрезаем -<дата>-<время>
+			if i := strings.LastIndex(container, "-"); i > 0 {
+				if j := strings.LastIndex(container[:i], "-"); j > 0 {
+					container = container[:j]
+				}
+			}
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		files = append(files, CaptureFile{
+			Path:       filepath.ToSlash(rel),
+			Session:    parts[0],
+			Pod:        parts[1],
+			Container:  container,
+			Size:       info.Size(),
+			ModTime:    info.ModTime(),
+			Compressed: compressed,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime.After(files[j].ModTime)
+	})
+	return files, nil
+}
+
+// ResolveCaptureFile - абсолютный путь файла захвата; false, если путь выходит за LOG_CAPTURE_DIR
+func (m *CaptureManager) ResolveCaptureFile(rel string) (string, bool) {
+	root, err := filepath.Abs(m.config.Dir)
+	if err != nil {
+		return "", false
+	}
+	path := filepath.Join(root, filepath.FromSlash(rel))
+	if !strings.HasPrefix(path, root+string(filepath.Separator)) {
+		return "", false
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return "", false
+	}
+	return path, true
+}
+
+// OpenCaptureFile - чтение файла захвата; .gz распаковывается прозрачно
+func OpenCaptureFile(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return file, nil
+	}
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &gzipFileReader{Reader: gz, file: file}, nil
+}
+
+type gzipFileReader struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (r *gzipFileReader) Close() error {
+	r.Reader.Close()
+	return r.file.Close()
+}
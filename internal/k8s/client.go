@@ -9,8 +9,8 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	// "k8s.io/client-go/rest"
-	// "k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
@@ -22,7 +22,11 @@ var (
 func InitK8s() (*kubernetes.Clientset, *metricsv.Clientset) {
 	log.Println("🔧 Initializing Kubernetes client...")
 
-	config, err := getK8sConfig()
+	// Конфиг из кластера, иначе локальный kubeconfig
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		config, err = clientcmd.BuildConfigFromFlags("", KubeconfigPath())
+	}
 	if err != nil {
 		log.Printf("❌ Failed to get kubeconfig: %v", err)
 		return nil, nil
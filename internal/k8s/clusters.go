@@ -0,0 +1,197 @@
+package k8s
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// InClusterName - имя кластера, если k8s-manager запущен внутри пода
+const InClusterName = "in-cluster"
+
+// Cluster - подключение к одному кластеру (контексту kubeconfig)
+type Cluster struct {
+	Name          string
+	Context       string
+	Server        string
+	Namespace     string
+	Config        *rest.Config
+	Clientset     *kubernetes.Clientset
+	MetricsClient *metricsv.Clientset
+	Dynamic       dynamic.Interface
+	Mapper        meta.ResettableRESTMapper // GVK -> ресурс по discovery, кеш сбрасывается при промахе
+	Resources     *ResourceCache
+	Watches       *WatchHub
+}
+
+// ClusterRegistry - реестр всех кластеров из kubeconfig
+type ClusterRegistry struct {
+	clusters       map[string]*Cluster
+	order          []string
+	defaultCluster string
+	mu             sync.RWMutex
+}
+
+// KubeconfigPath - путь к kubeconfig из $KUBECONFIG или ~/.kube/config
+func KubeconfigPath() string {
+	kubeconfig := os.Getenv("KUBECONFIG")
+	if kubeconfig == "" {
+		home, _ := os.UserHomeDir()
+		kubeconfig = filepath.Join(home, ".kube", "config")
+	}
+	return kubeconfig
+}
+
+// LoadClusterRegistry - загружает все контексты из kubeconfig.
+// Если kubeconfig недоступен, используется in-cluster конфигурация.
+func LoadClusterRegistry(kubeconfig string) (*ClusterRegistry, error) {
+	registry := &ClusterRegistry{
+		clusters: make(map[string]*Cluster),
+	}
+
+	rawConfig, err := clientcmd.LoadFromFile(kubeconfig)
+	if err != nil {
+		log.Printf("⚠️  Failed to load kubeconfig %s: %v", kubeconfig, err)
+	} else {
+		names := make([]string, 0, len(rawConfig.Contexts))
+		for name := range rawConfig.Contexts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			config, err := clientcmd.NewNonInteractiveClientConfig(
+				*rawConfig, name, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+			if err != nil {
+				log.Printf("⚠️  Skipping context %s: %v", name, err)
+				continue
+			}
+
+			cluster, err := newCluster(name, config)
+			if err != nil {
+				log.Printf("⚠️  Skipping context %s: %v", name, err)
+				continue
+			}
+			cluster.Namespace = rawConfig.Contexts[name].Namespace
+			registry.add(cluster)
+		}
+
+		if _, ok := registry.clusters[rawConfig.CurrentContext]; ok {
+			registry.defaultCluster = rawConfig.CurrentContext
+		}
+	}
+
+	// Пробуем in-cluster конфиг, если kubeconfig пуст
+	if len(registry.order) == 0 {
+		config, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("no usable kubeconfig contexts and not running in cluster: %w", err)
+		}
+
+		cluster, err := newCluster(InClusterName, config)
+		if err != nil {
+			return nil, err
+		}
+		registry.add(cluster)
+	}
+
+	if registry.defaultCluster == "" {
+		registry.defaultCluster = registry.order[0]
+	}
+
+	log.Printf("🔧 Loaded %d cluster(s), default: %s", len(registry.order), registry.defaultCluster)
+
+	return registry, nil
+}
+
+func newCluster(name string, config *rest.Config) (*Cluster, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	metricsClient, err := metricsv.NewForConfig(config)
+	if err != nil {
+		log.Printf("⚠️  Failed to create metrics client for %s: %v", name, err)
+		metricsClient = nil
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(clientset.Discovery()))
+	resources := NewResourceCache(clientset)
+
+	return &Cluster{
+		Name:          name,
+		Context:       name,
+		Server:        config.Host,
+		Config:        config,
+		Clientset:     clientset,
+		MetricsClient: metricsClient,
+		Dynamic:       dynamicClient,
+		Mapper:        mapper,
+		Resources:     resources,
+		Watches:       NewWatchHub(clientset, resources),
+	}, nil
+}
+
+func (r *ClusterRegistry) add(cluster *Cluster) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.clusters[cluster.Name]; !exists {
+		r.order = append(r.order, cluster.Name)
+	}
+	r.clusters[cluster.Name] = cluster
+}
+
+// Get - возвращает кластер по имени, пустое имя означает кластер по умолчанию
+func (r *ClusterRegistry) Get(name string) (*Cluster, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if name == "" {
+		name = r.defaultCluster
+	}
+	cluster, exists := r.clusters[name]
+	return cluster, exists
+}
+
+// Default - кластер по умолчанию (current-context)
+func (r *ClusterRegistry) Default() *Cluster {
+	cluster, _ := r.Get("")
+	return cluster
+}
+
+// DefaultName - имя кластера по умолчанию
+func (r *ClusterRegistry) DefaultName() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.defaultCluster
+}
+
+// List - все кластеры в порядке загрузки
+func (r *ClusterRegistry) List() []*Cluster {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clusters := make([]*Cluster, 0, len(r.order))
+	for _, name := range r.order {
+		clusters = append(clusters, r.clusters[name])
+	}
+	return clusters
+}
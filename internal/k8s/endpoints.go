@@ -0,0 +1,156 @@
+package k8s
+
+import (
+	"sort"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+)
+
+// EndpointAddress - адрес бэкенда сервиса из EndpointSlice
+type EndpointAddress struct {
+	IP          string `json:"ip"`
+	Ready       bool   `json:"ready"`
+	Serving     bool   `json:"serving"`
+	Terminating bool   `json:"terminating"`
+	Pod         string `json:"pod,omitempty"`
+	Node        string `json:"node,omitempty"`
+	Zone        string `json:"zone,omitempty"`
+}
+
+// EndpointPort - порт бэкенда (targetPort сервиса, разрешенный в номер)
+type EndpointPort struct {
+	Name     string `json:"name"`
+	Port     int32  `json:"port"`
+	Protocol string `json:"protocol"`
+}
+
+// ServiceEndpoints - адреса и порты сервиса из всех его EndpointSlice.
+// Ready/NotReady считают бэкенды (поды), а не IP: у dual-stack сервиса на под приходится по адресу
+// в IPv4- и IPv6-слайсах.
+type ServiceEndpoints struct {
+	Ready     int               `json:"ready"`
+	NotReady  int               `json:"notReady"`
+	Addresses []EndpointAddress `json:"addresses"`
+	Ports     []EndpointPort    `json:"ports"`
+}
+
+// ReadyPods - имена подов за готовыми адресами
+func (e *ServiceEndpoints) ReadyPods() []string {
+	pods := []string{}
+	seen := map[string]bool{}
+	for _, address := range e.Addresses {
+		if address.Ready && address.Pod != "" && !seen[address.Pod] {
+			seen[address.Pod] = true
+			pods = append(pods, address.Pod)
+		}
+	}
+	return pods
+}
+
+// IndexEndpointSlices - EndpointSlice, сгруппированные по сервису (метка kubernetes.io/service-name),
+// ключ - namespace/name сервиса
+func IndexEndpointSlices(slices []discoveryv1.EndpointSlice) map[string]*ServiceEndpoints {
+	index := make(map[string]*ServiceEndpoints)
+	// backends - готовность бэкенда по сервису и ключу (под или IP), seenIPs - уже добавленные адреса
+	backends := make(map[string]map[string]bool)
+	seenIPs := make(map[string]map[string]bool)
+
+	for _, slice := range slices {
+		service := slice.Labels[discoveryv1.LabelServiceName]
+		if service == "" {
+			continue
+		}
+		key := slice.Namespace + "/" + service
+
+		endpoints := index[key]
+		if endpoints == nil {
+			endpoints = &ServiceEndpoints{Addresses: []EndpointAddress{}, Ports: []EndpointPort{}}
+			index[key] = endpoints
+			backends[key] = make(map[string]bool)
+			seenIPs[key] = make(map[string]bool)
+		}
+
+		for _, port := range slice.Ports {
+			endpointPort := EndpointPort{}
+			if port.Name != nil {
+				endpointPort.Name = *port.Name
+			}
+			if port.Port != nil {
+				endpointPort.Port = *port.Port
+			}
+			if port.Protocol != nil {
+				endpointPort.Protocol = string(*port.Protocol)
+			}
+			if !hasEndpointPort(endpoints.Ports, endpointPort) {
+				endpoints.Ports = append(endpoints.Ports, endpointPort)
+			}
+		}
+
+		for _, endpoint := range slice.Endpoints {
+			// nil в условиях означает "неизвестно" и трактуется как готовность
+			ready := endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+			serving := endpoint.Conditions.Serving == nil || *endpoint.Conditions.Serving
+			terminating := endpoint.Conditions.Terminating != nil && *endpoint.Conditions.Terminating
+
+			for _, ip := range endpoint.Addresses {
+				if seenIPs[key][ip] {
+					continue
+				}
+				seenIPs[key][ip] = true
+
+				address := EndpointAddress{
+					IP:          ip,
+					Ready:       ready,
+					Serving:     serving,
+					Terminating: terminating,
+				}
+				if endpoint.TargetRef != nil && endpoint.TargetRef.Kind == "Pod" {
+					address.Pod = endpoint.TargetRef.Name
+				}
+				if endpoint.NodeName != nil {
+					address.Node = *endpoint.NodeName
+				}
+				if endpoint.Zone != nil {
+					address.Zone = *endpoint.Zone
+				}
+
+				endpoints.Addresses = append(endpoints.Addresses, address)
+
+				backend := "ip/" + ip
+				if address.Pod != "" {
+					backend = "pod/" + address.Pod
+				}
+				backends[key][backend] = backends[key][backend] || ready
+			}
+		}
+	}
+
+	for key, endpoints := range index {
+		for _, ready := range backends[key] {
+			if ready {
+				endpoints.Ready++
+			} else {
+				endpoints.NotReady++
+			}
+		}
+
+		sort.SliceStable(endpoints.Addresses, func(i, j int) bool {
+			a, b := endpoints.Addresses[i], endpoints.Addresses[j]
+			if a.Ready != b.Ready {
+				return a.Ready
+			}
+			return a.Pod < b.Pod
+		})
+	}
+
+	return index
+}
+
+func hasEndpointPort(ports []EndpointPort, port EndpointPort) bool {
+	for _, existing := range ports {
+		if existing == port {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package k8s
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// EventCount - число повторений события (series для events.k8s.io-совместимых записей)
+func EventCount(event *corev1.Event) int32 {
+	if event.Series != nil {
+		return event.Series.Count
+	}
+	if event.Count == 0 {
+		return 1
+	}
+	return event.Count
+}
+
+// EventFirstSeen - время первого появления события
+func EventFirstSeen(event *corev1.Event) time.Time {
+	switch {
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// EventLastSeen - время последнего повторения события
+func EventLastSeen(event *corev1.Event) time.Time {
+	switch {
+	case event.Series != nil:
+		return event.Series.LastObservedTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// EventSource - компонент, создавший событие
+func EventSource(event *corev1.Event) string {
+	if event.Source.Component != "" {
+		return event.Source.Component
+	}
+	return event.ReportingController
+}
@@ -0,0 +1,257 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+type ExecSession struct {
+	ID        string    `json:"id"`
+	Mode      string    `json:"mode"` // exec, attach
+	Cluster   string    `json:"cluster"`
+	Namespace string    `json:"namespace"`
+	Pod       string    `json:"pod"`
+	Container string    `json:"container"`
+	Command   []string  `json:"command"`
+	TTY       bool      `json:"tty"`
+	Status    string    `json:"status"` // running, stopped, error
+	CreatedAt time.Time `json:"createdAt"`
+
+	lastActivity atomic.Int64
+	cancel       context.CancelFunc
+	mu           sync.RWMutex // Status пишет горутина exec
+}
+
+// CurrentStatus - статус сессии (running, stopped, error)
+func (s *ExecSession) CurrentStatus() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Status
+}
+
+func (s *ExecSession) setStatus(status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Status = status
+}
+
+// Touch - отметка активности (ввод или вывод) для idle-таймаута
+func (s *ExecSession) Touch() {
+	s.lastActivity.Store(time.Now().UnixNano())
+}
+
+// LastActivity - время последнего ввода/вывода
+func (s *ExecSession) LastActivity() time.Time {
+	return time.Unix(0, s.lastActivity.Load())
+}
+
+// IdleFor - сколько сессия простаивает
+func (s *ExecSession) IdleFor() time.Duration {
+	return time.Since(s.LastActivity())
+}
+
+// Stop - прерывает exec; повторный вызов безопасен
+func (s *ExecSession) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+type ExecManager struct {
+	sessions map[string]*ExecSession
+	mu       sync.RWMutex
+}
+
+var execManager = &ExecManager{
+	sessions: make(map[string]*ExecSession),
+}
+
+func GetExecManager() *ExecManager {
+	return execManager
+}
+
+func (m *ExecManager) GetSessions() []*ExecSession {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	sessions := make([]*ExecSession, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+func (m *ExecManager) AddSession(session *ExecSession) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID] = session
+}
+
+func (m *ExecManager) RemoveSession(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+}
+
+func (m *ExecManager) GetSession(id string) (*ExecSession, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	session, exists := m.sessions[id]
+	return session, exists
+}
+
+func (m *ExecManager) StopSession(id string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	session, exists := m.sessions[id]
+	if !exists {
+		return false
+	}
+
+	session.Stop()
+	return true
+}
+
+func GenerateExecSessionID(cluster, namespace, pod, container string) string {
+	return fmt.Sprintf("%s-%s-%s-%s-%d",
+		unsafePathChars.ReplaceAllString(cluster, "_"), namespace, pod, container, time.Now().UnixNano())
+}
+
+// NewExecSession - сессия, привязанная к ctx; Stop() отменяет возвращенный контекст
+func NewExecSession(ctx context.Context, mode, cluster, namespace, pod, container string, command []string, tty bool) (*ExecSession, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	session := &ExecSession{
+		ID:        GenerateExecSessionID(cluster, namespace, pod, container),
+		Mode:      mode,
+		Cluster:   cluster,
+		Namespace: namespace,
+		Pod:       pod,
+		Container: container,
+		Command:   command,
+		TTY:       tty,
+		Status:    "running",
+		CreatedAt: time.Now(),
+		cancel:    cancel,
+	}
+	session.Touch()
+
+	return session, ctx
+}
+
+// StartExec - выполняет команду в контейнере и связывает ее с потоками до завершения или отмены ctx
+func StartExec(ctx context.Context, session *ExecSession, cluster *Cluster, streams remotecommand.StreamOptions) error {
+	log.Printf("🚀 Starting exec in %s/%s/%s [%s]: %v",
+		session.Cluster, session.Namespace, session.Pod, session.Container, session.Command)
+
+	executor, err := newExecutor(cluster, session.Namespace, session.Pod, "exec", &corev1.PodExecOptions{
+		Container: session.Container,
+		Command:   session.Command,
+		Stdin:     streams.Stdin != nil,
+		Stdout:    streams.Stdout != nil,
+		Stderr:    streams.Stderr != nil && !streams.Tty,
+		TTY:       streams.Tty,
+	})
+	if err != nil {
+		session.setStatus("error")
+		return err
+	}
+
+	err = executor.StreamWithContext(ctx, streams)
+	if err != nil && ctx.Err() == nil {
+		session.setStatus("error")
+	} else {
+		session.setStatus("stopped")
+	}
+
+	log.Printf("🛑 Exec finished in %s/%s [%s]", session.Namespace, session.Pod, session.Container)
+	return err
+}
+
+// ExecStream - неинтерактивный exec (без TTY): stdin/stdout/stderr могут быть nil
+func ExecStream(ctx context.Context, cluster *Cluster, namespace, pod, container string, command []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	executor, err := newExecutor(cluster, namespace, pod, "exec", &corev1.PodExecOptions{
+		Container: container,
+		Command:   command,
+		Stdin:     stdin != nil,
+		Stdout:    stdout != nil,
+		Stderr:    stderr != nil,
+	})
+	if err != nil {
+		return err
+	}
+
+	return executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+}
+
+// StartAttach - подключается к процессу контейнера (pods/attach), например к ephemeral debug-контейнеру
+func StartAttach(ctx context.Context, session *ExecSession, cluster *Cluster, streams remotecommand.StreamOptions) error {
+	log.Printf("🚀 Attaching to %s/%s/%s [%s]",
+		session.Cluster, session.Namespace, session.Pod, session.Container)
+
+	executor, err := newExecutor(cluster, session.Namespace, session.Pod, "attach", &corev1.PodAttachOptions{
+		Container: session.Container,
+		Stdin:     streams.Stdin != nil,
+		Stdout:    streams.Stdout != nil,
+		Stderr:    streams.Stderr != nil && !streams.Tty,
+		TTY:       streams.Tty,
+	})
+	if err != nil {
+		session.setStatus("error")
+		return err
+	}
+
+	err = executor.StreamWithContext(ctx, streams)
+	if err != nil && ctx.Err() == nil {
+		session.setStatus("error")
+	} else {
+		session.setStatus("stopped")
+	}
+
+	log.Printf("🛑 Attach finished in %s/%s [%s]", session.Namespace, session.Pod, session.Container)
+	return err
+}
+
+// newExecutor - executor для pods/exec и pods/attach: сначала WebSocket-протокол API-сервера, при отказе апгрейда - SPDY
+func newExecutor(cluster *Cluster, namespace, pod, subresource string, options runtime.Object) (remotecommand.Executor, error) {
+	req := cluster.Clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource(subresource).
+		VersionedParams(options, scheme.ParameterCodec)
+
+	// Конфиг кластера, к которому относится сессия
+	config := cluster.Config
+
+	spdyExec, err := remotecommand.NewSPDYExecutor(config, http.MethodPost, req.URL())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SPDY executor: %w", err)
+	}
+
+	wsExec, err := remotecommand.NewWebSocketExecutor(config, http.MethodGet, req.URL().String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create WebSocket executor: %w", err)
+	}
+
+	return remotecommand.NewFallbackExecutor(wsExec, spdyExec, func(err error) bool {
+		return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+	})
+}
@@ -0,0 +1,155 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// crdEstablishTimeout - сколько ждать регистрации новых CRD перед применением custom resources
+const crdEstablishTimeout = 30 * time.Second
+
+var crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// ManifestResult - результат применения одного объекта манифеста
+type ManifestResult struct {
+	Index      int             `json:"index"` // номер объекта в манифесте
+	APIVersion string          `json:"apiVersion"`
+	Kind       string          `json:"kind"`
+	Name       string          `json:"name"`
+	Namespace  string          `json:"namespace,omitempty"`
+	Action     string          `json:"action"` // created, configured, unchanged или error
+	Error      string          `json:"error,omitempty"`
+	Conflicts  []ApplyConflict `json:"conflicts,omitempty"`
+}
+
+// DecodeManifest - все объекты многодокументного YAML (или JSON); пустые документы пропускаются,
+// kind: List раскрывается в элементы
+func DecodeManifest(data []byte) ([]*unstructured.Unstructured, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+
+	var objects []*unstructured.Unstructured
+	for doc := 1; ; doc++ {
+		var raw map[string]interface{}
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("document %d: %w", doc, err)
+		}
+		if len(raw) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: raw}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, fmt.Errorf("document %d: %w", doc, err)
+			}
+			for i := range list.Items {
+				objects = append(objects, &list.Items[i])
+			}
+			continue
+		}
+		objects = append(objects, obj)
+	}
+
+	return objects, nil
+}
+
+// applyPriority - Namespace и CRD применяются раньше объектов, которые от них зависят
+func applyPriority(obj *unstructured.Unstructured) int {
+	switch obj.GroupVersionKind().GroupKind() {
+	case schema.GroupKind{Kind: "Namespace"}:
+		return 0
+	case schema.GroupKind{Group: crdResource.Group, Kind: "CustomResourceDefinition"}:
+		return 1
+	}
+	return 2
+}
+
+// ApplyManifest - server-side apply всех объектов манифеста: сначала Namespace, затем CRD
+// (с ожиданием их регистрации), затем остальные в исходном порядке. Ошибка одного объекта
+// не останавливает применение остальных.
+func (c *Cluster) ApplyManifest(ctx context.Context, objects []*unstructured.Unstructured, defaultNamespace string, opts ApplyOptions) []ManifestResult {
+	order := make([]int, len(objects))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return applyPriority(objects[order[a]]) < applyPriority(objects[order[b]])
+	})
+
+	results := make([]ManifestResult, 0, len(objects))
+	var crds []string
+	crdsReady := false
+
+	for _, i := range order {
+		obj := objects[i]
+
+		if applyPriority(obj) > 1 && !crdsReady {
+			crdsReady = true
+			if len(crds) > 0 && !opts.DryRun {
+				c.waitCRDsEstablished(ctx, crds)
+			}
+		}
+
+		result := ManifestResult{
+			Index:      i,
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Name:       obj.GetName(),
+		}
+
+		applied, err := c.Apply(ctx, obj, defaultNamespace, opts)
+		if err != nil {
+			result.Namespace = obj.GetNamespace()
+			result.Action = ApplyFailed
+			result.Error = err.Error()
+			result.Conflicts = ApplyConflicts(err)
+		} else {
+			result.Namespace = applied.Object.GetNamespace()
+			result.Action = applied.Action
+			if applyPriority(obj) == 1 {
+				crds = append(crds, obj.GetName())
+			}
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// waitCRDsEstablished - ждет условия Established у CRD и сбрасывает кеш discovery,
+// чтобы новые виды ресурсов нашлись при применении custom resources
+func (c *Cluster) waitCRDsEstablished(ctx context.Context, names []string) {
+	defer c.Mapper.Reset()
+
+	for _, name := range names {
+		_ = wait.PollUntilContextTimeout(ctx, 500*time.Millisecond, crdEstablishTimeout, true, func(ctx context.Context) (bool, error) {
+			crd, err := c.Dynamic.Resource(crdResource).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return false, nil
+			}
+			conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+			for _, item := range conditions {
+				condition, ok := item.(map[string]interface{})
+				if ok && condition["type"] == "Established" && condition["status"] == "True" {
+					return true, nil
+				}
+			}
+			return false, nil
+		})
+	}
+}
@@ -8,7 +8,6 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
 	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
 )
@@ -49,7 +48,7 @@ type ClusterMetrics struct {
 	ClusterMemoryPercent   int
 }
 
-func GetPodMetrics(metricsClient *metricsv.Clientset, clientset *kubernetes.Clientset, namespace string) ([]PodMetrics, error) {
+func GetPodMetrics(metricsClient *metricsv.Clientset, resources *ResourceCache, namespace string, live bool) ([]PodMetrics, error) {
 	if metricsClient == nil {
 		return nil, fmt.Errorf("metrics client not initialized")
 	}
@@ -61,7 +60,7 @@ func GetPodMetrics(metricsClient *metricsv.Clientset, clientset *kubernetes.Clie
 	}
 
 	// Получаем список подов для дополнительной информации
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	pods, err := resources.ListPods(context.TODO(), namespace, live)
 	if err != nil {
 		return nil, err
 	}
@@ -155,7 +154,7 @@ func GetPodMetrics(metricsClient *metricsv.Clientset, clientset *kubernetes.Clie
 	return metrics, nil
 }
 
-func GetSinglePodMetrics(metricsClient *metricsv.Clientset, clientset *kubernetes.Clientset, namespace, podName string) (*metricsv1beta1.PodMetrics, *corev1.Pod, error) {
+func GetSinglePodMetrics(metricsClient *metricsv.Clientset, resources *ResourceCache, namespace, podName string, live bool) (*metricsv1beta1.PodMetrics, *corev1.Pod, error) {
 	// Получаем метрики пода
 	podMetrics, err := metricsClient.MetricsV1beta1().PodMetricses(namespace).Get(context.TODO(), podName, metav1.GetOptions{})
 	if err != nil {
@@ -163,7 +162,7 @@ func GetSinglePodMetrics(metricsClient *metricsv.Clientset, clientset *kubernete
 	}
 
 	// Получаем информацию о поде
-	pod, err := clientset.CoreV1().Pods(namespace).Get(context.TODO(), podName, metav1.GetOptions{})
+	pod, err := resources.GetPod(context.TODO(), namespace, podName, live)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -171,7 +170,7 @@ func GetSinglePodMetrics(metricsClient *metricsv.Clientset, clientset *kubernete
 	return podMetrics, pod, nil
 }
 
-func GetNodeMetrics(metricsClient *metricsv.Clientset, clientset *kubernetes.Clientset) ([]NodeMetrics, *ClusterMetrics, error) {
+func GetNodeMetrics(metricsClient *metricsv.Clientset, resources *ResourceCache, live bool) ([]NodeMetrics, *ClusterMetrics, error) {
 	// Получаем метрики нод
 	nodeMetricsList, err := metricsClient.MetricsV1beta1().NodeMetricses().List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
@@ -179,7 +178,7 @@ func GetNodeMetrics(metricsClient *metricsv.Clientset, clientset *kubernetes.Cli
 	}
 
 	// Получаем информацию о нодах
-	nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	nodes, err := resources.ListNodes(context.TODO(), live)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -253,9 +252,9 @@ func GetNodeMetrics(metricsClient *metricsv.Clientset, clientset *kubernetes.Cli
 	return metrics, &clusterMetrics, nil
 }
 
-func GetAllPodsMetrics(metricsClient *metricsv.Clientset, clientset *kubernetes.Clientset) ([]map[string]interface{}, int64, int64, error) {
+func GetAllPodsMetrics(metricsClient *metricsv.Clientset, resources *ResourceCache, live bool) ([]map[string]interface{}, int64, int64, error) {
 	// Получаем все namespaces
-	namespaces, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	namespaces, err := resources.ListNamespaces(context.TODO(), live)
 	if err != nil {
 		return nil, 0, 0, err
 	}
@@ -0,0 +1,307 @@
+package k8s
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Типы событий PodLogFollower
+const (
+	PodLogLine    = "line"
+	PodLogJoined  = "joined"
+	PodLogLeft    = "left"
+	PodLogWarning = "warning"
+)
+
+// PodLogEvent - строка лога или смена состава контейнеров
+type PodLogEvent struct {
+	Type      string
+	Pod       string
+	Container string
+	Time      time.Time // метка kubelet; нулевая, если строки без метки
+	Text      string    // текст без метки времени
+	Err       error
+}
+
+// PodLogFollower - follow логов всех контейнеров подов по селектору.
+// Новые поды и перезапущенные контейнеры подключаются по watch, после рестарта чтение
+// продолжается с последней метки времени без повтора строк.
+type PodLogFollower struct {
+	Clientset       *kubernetes.Clientset
+	Namespace       string
+	Selector        string
+	FieldSelector   string                      // например metadata.name=<pod> для одного пода
+	ContainerFilter func(container string) bool // nil - все контейнеры
+	Options         corev1.PodLogOptions        // Container, Follow и Timestamps задаются follower'ом
+	Since           time.Time                   // возобновление: строки не новее уже получены
+}
+
+// Run - блокируется до отмены ctx или ошибки watch; события отправляются в events
+func (f *PodLogFollower) Run(ctx context.Context, events chan<- PodLogEvent) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	tailers := make(map[string]context.CancelFunc) // pod/container -> остановка tail
+	lastSeen := make(map[string]time.Time)         // время последней строки для продолжения после рестарта
+
+	send := func(event PodLogEvent) {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+		}
+	}
+
+	tail := func(tailCtx context.Context, podName, container string) {
+		key := podName + "/" + container
+
+		mu.Lock()
+		since, resumed := lastSeen[key]
+		mu.Unlock()
+		if !resumed && !f.Since.IsZero() {
+			since, resumed = f.Since, true
+		}
+
+		opts := f.Options
+		opts.Container = container
+		opts.Follow = true
+		opts.Timestamps = true
+		if resumed {
+			opts.TailLines = nil
+			opts.SinceSeconds = nil
+			sinceTime := metav1.NewTime(since)
+			opts.SinceTime = &sinceTime
+		}
+
+		last := since
+		defer func() {
+			mu.Lock()
+			delete(tailers, key)
+			lastSeen[key] = last
+			mu.Unlock()
+			send(PodLogEvent{Type: PodLogLeft, Pod: podName, Container: container})
+		}()
+
+		podLogs, err := f.Clientset.CoreV1().Pods(f.Namespace).GetLogs(podName, &opts).Stream(tailCtx)
+		if err != nil {
+			send(PodLogEvent{Type: PodLogWarning, Pod: podName, Container: container, Err: err})
+			return
+		}
+		defer podLogs.Close()
+
+		send(PodLogEvent{Type: PodLogJoined, Pod: podName, Container: container})
+
+		scanner := bufio.NewScanner(podLogs)
+		const maxCapacity = 1024 * 1024 // 1MB
+		scanner.Buffer(make([]byte, 64*1024), maxCapacity)
+
+		for scanner.Scan() {
+			t, text, ok := SplitLogTimestamp(scanner.Text())
+			if ok {
+				// sinceTime с точностью до секунды - отбрасываем уже прочитанное
+				if resumed && !t.After(since) {
+					continue
+				}
+				last = t
+			}
+
+			send(PodLogEvent{Type: PodLogLine, Pod: podName, Container: container, Time: t, Text: text})
+		}
+	}
+
+	// attach - запускает tail для запущенных контейнеров пода, которые еще не читаются
+	attach := func(pod *corev1.Pod) {
+		if pod.DeletionTimestamp != nil {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		for _, container := range LogContainers(pod) {
+			if f.ContainerFilter != nil && !f.ContainerFilter(container) {
+				continue
+			}
+			if !ContainerRunning(pod, container) {
+				continue
+			}
+
+			key := pod.Name + "/" + container
+			if _, active := tailers[key]; active {
+				continue
+			}
+
+			tailCtx, tailCancel := context.WithCancel(ctx)
+			tailers[key] = tailCancel
+			go tail(tailCtx, pod.Name, container)
+		}
+	}
+
+	// detach - останавливает tail удаленного пода
+	detach := func(podName string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		for key, stop := range tailers {
+			if strings.HasPrefix(key, podName+"/") {
+				stop()
+			}
+		}
+		for key := range lastSeen {
+			if strings.HasPrefix(key, podName+"/") {
+				delete(lastSeen, key)
+			}
+		}
+	}
+
+	listOpts := metav1.ListOptions{LabelSelector: f.Selector, FieldSelector: f.FieldSelector}
+
+	openWatch := func() (watch.Interface, error) {
+		pods, err := f.Clientset.CoreV1().Pods(f.Namespace).List(ctx, listOpts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range pods.Items {
+			attach(&pods.Items[i])
+		}
+
+		watchOpts := listOpts
+		watchOpts.ResourceVersion = pods.ResourceVersion
+		return f.Clientset.CoreV1().Pods(f.Namespace).Watch(ctx, watchOpts)
+	}
+
+	watcher, err := openWatch()
+	if err != nil {
+		return err
+	}
+	defer func() { watcher.Stop() }()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				// API-сервер закрыл watch - переоткрываем со свежим списком
+				watcher.Stop()
+				if watcher, err = openWatch(); err != nil {
+					if ctx.Err() != nil {
+						return nil
+					}
+					return fmt.Errorf("failed to re-watch pods: %w", err)
+				}
+				continue
+			}
+
+			pod, ok := event.Object.(*corev1.Pod)
+			if !ok {
+				continue
+			}
+
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				attach(pod)
+			case watch.Deleted:
+				detach(pod.Name)
+			}
+		}
+	}
+}
+
+// SplitLogTimestamp - отделяет RFC3339Nano-метку, которую добавляет kubelet
+func SplitLogTimestamp(line string) (time.Time, string, bool) {
+	stamp, rest, found := strings.Cut(line, " ")
+	if !found {
+		stamp, rest = line, ""
+	}
+	t, err := time.Parse(time.RFC3339Nano, stamp)
+	if err != nil {
+		return time.Time{}, line, false
+	}
+	return t, rest, true
+}
+
+// LogContainers - все контейнеры пода в порядке запуска: init, обычные, ephemeral
+func LogContainers(pod *corev1.Pod) []string {
+	var names []string
+	for _, container := range pod.Spec.InitContainers {
+		names = append(names, container.Name)
+	}
+	for _, container := range pod.Spec.Containers {
+		names = append(names, container.Name)
+	}
+	for _, container := range pod.Spec.EphemeralContainers {
+		names = append(names, container.Name)
+	}
+	return names
+}
+
+// ContainerRunning - запущен ли контейнер пода (init, обычный или ephemeral)
+func ContainerRunning(pod *corev1.Pod, container string) bool {
+	for _, statuses := range [][]corev1.ContainerStatus{
+		pod.Status.InitContainerStatuses,
+		pod.Status.ContainerStatuses,
+		pod.Status.EphemeralContainerStatuses,
+	} {
+		for _, status := range statuses {
+			if status.Name == container {
+				return status.State.Running != nil
+			}
+		}
+	}
+	return false
+}
+
+// ContainerFinished - контейнер завершился и перезапущен не будет (init-контейнер отработал,
+// restartPolicy Never/OnFailure, ephemeral-контейнер); новых строк в его логе не появится
+func ContainerFinished(pod *corev1.Pod, container string) bool {
+	for _, status := range pod.Status.EphemeralContainerStatuses {
+		if status.Name == container {
+			return status.State.Terminated != nil
+		}
+	}
+
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.Name != container {
+			continue
+		}
+		terminated := status.State.Terminated
+		if terminated == nil {
+			return false
+		}
+		// Sidecar (init-контейнер с restartPolicy: Always) перезапускается, как обычный
+		for _, spec := range pod.Spec.InitContainers {
+			if spec.Name == container && spec.RestartPolicy != nil && *spec.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+				return containerWontRestart(pod, terminated)
+			}
+		}
+		return terminated.ExitCode == 0 || pod.Spec.RestartPolicy == corev1.RestartPolicyNever
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == container {
+			return status.State.Terminated != nil && containerWontRestart(pod, status.State.Terminated)
+		}
+	}
+	return false
+}
+
+func containerWontRestart(pod *corev1.Pod, terminated *corev1.ContainerStateTerminated) bool {
+	switch pod.Spec.RestartPolicy {
+	case corev1.RestartPolicyNever:
+		return true
+	case corev1.RestartPolicyOnFailure:
+		return terminated.ExitCode == 0
+	}
+	return false
+}
@@ -1,34 +1,144 @@
 package k8s
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
-	"path/filepath"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
-	"k8s.io/client-go/tools/clientcmd"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/portforward"
 	"k8s.io/client-go/transport/spdy"
 )
 
+// Цели port-forward
+const (
+	PortForwardPod         = "pod"
+	PortForwardService     = "service"
+	PortForwardDeployment  = "deployment"
+	PortForwardStatefulSet = "statefulset"
+)
+
+// Параметры переключения на другой под
+const (
+	pfReadyTimeout     = 10 * time.Second // ожидание готовности туннеля
+	pfPodCheckInterval = 2 * time.Second  // проверка, что под еще жив и готов
+	pfRetryMin         = 1 * time.Second  // пауза перед повторным выбором пода
+	pfRetryMax         = 10 * time.Second // потолок паузы
+	pfStableAfter      = 30 * time.Second // туннель, проживший столько, сбрасывает паузу
+)
+
 type PortForwardSession struct {
 	ID         string    `json:"id"`
+	Cluster    string    `json:"cluster"`
 	Pod        string    `json:"pod"`
 	Namespace  string    `json:"namespace"`
 	LocalPort  int       `json:"localPort"`
 	RemotePort int       `json:"remotePort"`
-	Status     string    `json:"status"` // running, stopped, error
+	Status     string    `json:"status"` // starting, running, reconnecting, stopped, error
 	CreatedAt  time.Time `json:"createdAt"`
 	StartedAt  time.Time `json:"startedAt,omitempty"`
 	URL        string    `json:"url"`
 	StopChan   chan struct{}
+
+	// Цель сессии; для service/deployment/statefulset под выбирается заново, когда текущий умирает
+	TargetKind string `json:"targetKind"`
+	TargetName string `json:"targetName"`
+	TargetPort int    `json:"targetPort"` // порт сервиса или контейнера, как в запросе
+	Failovers  int    `json:"failovers"`
+	LastError  string `json:"lastError,omitempty"`
+
+	mu       sync.RWMutex
+	stopOnce sync.Once
+}
+
+// PortForwardState - изменяемая часть сессии (под и порт меняются при failover)
+type PortForwardState struct {
+	Pod        string
+	RemotePort int
+	Status     string
+	StartedAt  time.Time
+	Failovers  int
+	LastError  string
+}
+
+// State - снимок текущего состояния сессии
+func (s *PortForwardSession) State() PortForwardState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return PortForwardState{
+		Pod:        s.Pod,
+		RemotePort: s.RemotePort,
+		Status:     s.Status,
+		StartedAt:  s.StartedAt,
+		Failovers:  s.Failovers,
+		LastError:  s.LastError,
+	}
+}
+
+func (s *PortForwardSession) setStatus(status string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Status = status
+	if err != nil {
+		s.LastError = err.Error()
+	}
+}
+
+// markRunning - туннель готов; StartedAt - время первого подключения
+func (s *PortForwardSession) markRunning() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Status = "running"
+	if s.StartedAt.IsZero() {
+		s.StartedAt = time.Now()
+	}
+}
+
+// WaitStarted - ждет результата первого подключения (running, reconnecting, error или stopped)
+func (s *PortForwardSession) WaitStarted() PortForwardState {
+	deadline := time.Now().Add(pfReadyTimeout + time.Second)
+	for {
+		state := s.State()
+		if state.Status != "starting" || time.Now().After(deadline) {
+			return state
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func (s *PortForwardSession) switchPod(pod string, port int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pod != s.Pod {
+		s.Failovers++
+	}
+	s.Pod = pod
+	s.RemotePort = port
+}
+
+// Stop - останавливает сессию; повторный вызов безопасен
+func (s *PortForwardSession) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.StopChan)
+	})
+}
+
+// Failover - цель допускает переключение на другой под
+func (s *PortForwardSession) Failover() bool {
+	return s.TargetKind != "" && s.TargetKind != PortForwardPod
 }
 
 type PortForwardManager struct {
@@ -84,7 +194,7 @@ func (m *PortForwardManager) StopSession(id string) bool {
 	}
 
 	if session.StopChan != nil {
-		close(session.StopChan)
+		session.Stop()
 	}
 
 	return true
@@ -103,54 +213,235 @@ func IsPortInUse(port int) bool {
 	return false
 }
 
-func GenerateSessionID(namespace, pod string, remotePort, localPort int) string {
-	return fmt.Sprintf("%s-%s-%d-%d-%d",
-		namespace, pod, remotePort, localPort, time.Now().Unix())
+func GenerateSessionID(cluster, namespace, pod string, remotePort, localPort int) string {
+	return fmt.Sprintf("%s-%s-%s-%d-%d-%d",
+		unsafePathChars.ReplaceAllString(cluster, "_"), namespace, pod, remotePort, localPort, time.Now().Unix())
+}
+
+// PodReady - под запущен, готов и не удаляется
+func PodReady(pod *corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// containerPortFor - порт контейнера пода для порта сервиса (targetPort по номеру или имени)
+func containerPortFor(pod *corev1.Pod, svcPort *corev1.ServicePort) (int, bool) {
+	target := svcPort.TargetPort
+	switch {
+	case target.Type == intstr.String:
+		for _, container := range pod.Spec.Containers {
+			for _, port := range container.Ports {
+				if port.Name == target.StrVal {
+					return int(port.ContainerPort), true
+				}
+			}
+		}
+		return 0, false
+	case target.IntVal == 0:
+		return int(svcPort.Port), true
+	}
+	return int(target.IntVal), true
 }
 
-func StartPortForward(session *PortForwardSession, clientset *kubernetes.Clientset) {
-	log.Printf("🚀 Starting port-forward for pod %s/%s: %d -> %d",
-		session.Namespace, session.Pod, session.LocalPort, session.RemotePort)
+// ResolvePortForwardPod - под и порт контейнера для цели port-forward.
+// Для service port - порт сервиса (targetPort разрешается по поду), для остальных целей - порт контейнера.
+// avoid - под, который только что отказал: он выбирается, только если других готовых нет.
+func ResolvePortForwardPod(ctx context.Context, clientset kubernetes.Interface, namespace, kind, name string, port int, avoid string) (string, int, error) {
+	var selector labels.Selector
+	var svcPort *corev1.ServicePort
+
+	switch kind {
+	case PortForwardPod:
+		pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", 0, err
+		}
+		if pod.Status.Phase != corev1.PodRunning {
+			return "", 0, fmt.Errorf("pod %s/%s is not running (current status: %s)", namespace, name, pod.Status.Phase)
+		}
+		return name, port, nil
+
+	case PortForwardService:
+		svc, err := clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", 0, err
+		}
+		if len(svc.Spec.Selector) == 0 {
+			return "", 0, fmt.Errorf("service %s/%s has no selector", namespace, name)
+		}
+		for i := range svc.Spec.Ports {
+			if int(svc.Spec.Ports[i].Port) == port {
+				svcPort = &svc.Spec.Ports[i]
+			}
+		}
+		if svcPort == nil {
+			return "", 0, fmt.Errorf("service %s/%s has no port %d", namespace, name, port)
+		}
+		selector = labels.SelectorFromSet(svc.Spec.Selector)
+
+	case PortForwardDeployment:
+		deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", 0, err
+		}
+		if selector, err = metav1.LabelSelectorAsSelector(deployment.Spec.Selector); err != nil {
+			return "", 0, err
+		}
+
+	case PortForwardStatefulSet:
+		sts, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", 0, err
+		}
+		if selector, err = metav1.LabelSelectorAsSelector(sts.Spec.Selector); err != nil {
+			return "", 0, err
+		}
+
+	default:
+		return "", 0, fmt.Errorf("unknown port-forward target %q", kind)
+	}
 
-	session.Status = "running"
-	session.StartedAt = time.Now()
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return "", 0, err
+	}
+
+	// Готовые поды, самые старые первыми: они реже попадают под раскатку
+	var candidates []corev1.Pod
+	for _, pod := range pods.Items {
+		if PodReady(&pod) {
+			candidates = append(candidates, pod)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+			return a.CreationTimestamp.Before(&b.CreationTimestamp)
+		}
+		return a.Name < b.Name
+	})
+
+	fallback, fallbackPort := "", 0
+	for i := range candidates {
+		pod := &candidates[i]
+		podPort := port
+		if svcPort != nil {
+			var ok bool
+			if podPort, ok = containerPortFor(pod, svcPort); !ok {
+				continue
+			}
+		}
+		if pod.Name == avoid {
+			fallback, fallbackPort = pod.Name, podPort
+			continue
+		}
+		return pod.Name, podPort, nil
+	}
+	if fallback != "" {
+		return fallback, fallbackPort, nil
+	}
+
+	return "", 0, fmt.Errorf("no ready pods for %s %s/%s", kind, namespace, name)
+}
+
+// StartPortForward - держит port-forward до остановки сессии. Для service/deployment/statefulset,
+// когда текущий под умирает или перестает быть готовым, выбирается другой готовый под.
+func StartPortForward(session *PortForwardSession, cluster *Cluster) {
+	log.Printf("🚀 Starting port-forward for pod %s/%s/%s: %d -> %d",
+		session.Cluster, session.Namespace, session.Pod, session.LocalPort, session.RemotePort)
 
 	defer func() {
-		session.Status = "stopped"
-		close(session.StopChan)
+		if session.State().Status != "error" {
+			session.setStatus("stopped", nil)
+		}
+		session.Stop()
 
 		// Удаляем сессию из менеджера
 		pfManager.RemoveSession(session.ID)
 
-		log.Printf("🛑 Port-forward stopped for pod %s/%s", session.Namespace, session.Pod)
+		log.Printf("🛑 Port-forward stopped for %s %s/%s", session.TargetKind, session.Namespace, session.TargetName)
 	}()
 
-	// Получаем конфиг
-	config, err := getK8sConfig()
-	if err != nil {
-		log.Printf("❌ Failed to get kubeconfig: %v", err)
-		session.Status = "error"
-		return
+	retry := pfRetryMin
+	for {
+		state := session.State()
+		attempt := time.Now()
+		ready, err := forwardToPod(session, cluster, state.Pod, state.RemotePort)
+
+		select {
+		case <-session.StopChan:
+			log.Printf("ℹ️ Port-forward manually stopped: %s/%s", session.Namespace, state.Pod)
+			return
+		default:
+		}
+
+		if !session.Failover() {
+			if err != nil {
+				session.setStatus("error", err)
+			}
+			return
+		}
+
+		// Пауза сбрасывается, только если туннель поработал; иначе повторяющийся отказ
+		// (порт еще занят, нет прав на pods/portforward) не превращается в цикл без пауз
+		if ready && time.Since(attempt) >= pfStableAfter {
+			retry = pfRetryMin
+		}
+
+		// Ищем другой готовый под, пока сессию не остановили
+		session.setStatus("reconnecting", err)
+		log.Printf("🔁 Port-forward %s: pod %s lost (%v), looking for another ready pod", session.ID, state.Pod, err)
+		for {
+			select {
+			case <-session.StopChan:
+				return
+			case <-time.After(retry):
+			}
+			retry = min(retry*2, pfRetryMax)
+
+			ctx, cancel := context.WithTimeout(context.Background(), pfReadyTimeout)
+			pod, port, resolveErr := ResolvePortForwardPod(ctx, cluster.Clientset, session.Namespace,
+				session.TargetKind, session.TargetName, session.TargetPort, state.Pod)
+			cancel()
+
+			if resolveErr == nil {
+				session.switchPod(pod, port)
+				log.Printf("🔁 Port-forward %s: reconnecting to pod %s:%d", session.ID, pod, port)
+				break
+			}
+			session.setStatus("reconnecting", resolveErr)
+		}
 	}
+}
+
+// forwardToPod - один туннель к поду; возвращается при остановке сессии, обрыве соединения
+// или когда под удален, перестал работать или (для failover-целей) стал неготовым.
+// ready - туннель успел подключиться.
+func forwardToPod(session *PortForwardSession, cluster *Cluster, podName string, remotePort int) (bool, error) {
+	// Конфиг кластера, к которому относится сессия
+	config := cluster.Config
 
 	// Создаем round tripper для SPDY
 	roundTripper, upgrader, err := spdy.RoundTripperFor(config)
 	if err != nil {
-		log.Printf("❌ Failed to create round tripper: %v", err)
-		session.Status = "error"
-		return
+		return false, fmt.Errorf("failed to create round tripper: %w", err)
 	}
 
 	// URL для port-forward
 	path := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/portforward",
-		session.Namespace, session.Pod)
+		session.Namespace, podName)
 
 	// Получаем хост из конфига
 	hostURL, err := url.Parse(config.Host)
 	if err != nil {
-		log.Printf("❌ Failed to parse host URL: %v", err)
-		session.Status = "error"
-		return
+		return false, fmt.Errorf("failed to parse host URL: %w", err)
 	}
 
 	// Создаем полный URL для порт-форвардинга
@@ -165,17 +456,19 @@ func StartPortForward(session *PortForwardSession, clientset *kubernetes.Clients
 		http.MethodPost, serverURL)
 
 	// Порт для форвардинга
-	ports := []string{fmt.Sprintf("%d:%d", session.LocalPort, session.RemotePort)}
+	ports := []string{fmt.Sprintf("%d:%d", session.LocalPort, remotePort)}
+
+	// Туннель закрывается при остановке сессии или когда под перестал годиться
+	stopChan := make(chan struct{})
+	podLost := make(chan error, 1)
+	defer close(stopChan)
 
-	// Каналы для ошибок
 	readyChan := make(chan struct{}, 1)
 
 	// Запускаем port-forward
-	pf, err := portforward.New(dialer, ports, session.StopChan, readyChan, os.Stdout, os.Stderr)
+	pf, err := portforward.New(dialer, ports, stopChan, readyChan, os.Stdout, os.Stderr)
 	if err != nil {
-		log.Printf("❌ Failed to create port forward: %v", err)
-		session.Status = "error"
-		return
+		return false, fmt.Errorf("failed to create port forward: %w", err)
 	}
 
 	// Запускаем в горутине
@@ -188,52 +481,70 @@ func StartPortForward(session *PortForwardSession, clientset *kubernetes.Clients
 	select {
 	case <-readyChan:
 		log.Printf("✅ Port-forward ready: %s/%s %d->%d",
-			session.Namespace, session.Pod, session.LocalPort, session.RemotePort)
-
-		// Обновляем статус
-		session.Status = "running"
+			session.Namespace, podName, session.LocalPort, remotePort)
+		session.markRunning()
 
 	case err := <-errChan:
-		log.Printf("❌ Port-forward error: %v", err)
-		session.Status = "error"
-		return
+		if err == nil {
+			err = fmt.Errorf("connection to pod %s closed", podName)
+		}
+		return false, err
 
-	case <-time.After(10 * time.Second):
-		log.Printf("❌ Port-forward timeout")
-		session.Status = "error"
-		return
+	case <-session.StopChan:
+		return false, nil
+
+	case <-time.After(pfReadyTimeout):
+		return false, fmt.Errorf("port-forward to %s timed out", podName)
 	}
 
+	go watchForwardedPod(session, cluster, podName, stopChan, podLost)
+
 	// Ждем остановки
 	select {
 	case err := <-errChan:
-		if err != nil {
-			log.Printf("❌ Port-forward stopped with error: %v", err)
-			session.Status = "error"
-		} else {
-			log.Printf("ℹ️ Port-forward completed normally")
-			session.Status = "stopped"
+		if err == nil {
+			err = fmt.Errorf("connection to pod %s closed", podName)
 		}
+		return true, err
+
+	case err := <-podLost:
+		return true, err
 
 	case <-session.StopChan:
-		log.Printf("ℹ️ Port-forward manually stopped: %s/%s", session.Namespace, session.Pod)
-		session.Status = "stopped"
+		return true, nil
 	}
 }
 
-func getK8sConfig() (*rest.Config, error) {
-	// Попробовать получить конфиг из кластера
-	config, err := rest.InClusterConfig()
-	if err == nil {
-		return config, nil
-	}
+// watchForwardedPod - периодически проверяет под туннеля; сообщает в lost, если он удален,
+// не работает или (для failover-целей) не готов
+func watchForwardedPod(session *PortForwardSession, cluster *Cluster, podName string, done <-chan struct{}, lost chan<- error) {
+	ticker := time.NewTicker(pfPodCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
 
-	// Использовать локальный kubeconfig
-	kubeconfig := os.Getenv("KUBECONFIG")
-	if kubeconfig == "" {
-		home, _ := os.UserHomeDir()
-		kubeconfig = filepath.Join(home, ".kube", "config")
+		ctx, cancel := context.WithTimeout(context.Background(), pfPodCheckInterval)
+		pod, err := cluster.Clientset.CoreV1().Pods(session.Namespace).Get(ctx, podName, metav1.GetOptions{})
+		cancel()
+
+		switch {
+		case apierrors.IsNotFound(err):
+			lost <- fmt.Errorf("pod %s deleted", podName)
+			return
+		case err != nil:
+			// API временно недоступен - туннель может работать дальше
+			continue
+		case pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning:
+			lost <- fmt.Errorf("pod %s is terminating (phase %s)", podName, pod.Status.Phase)
+			return
+		case session.Failover() && !PodReady(pod):
+			lost <- fmt.Errorf("pod %s is not ready", podName)
+			return
+		}
 	}
-
-	return clientcmd.BuildConfigFromFlags("", kubeconfig)
 }
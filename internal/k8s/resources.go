@@ -0,0 +1,156 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// APIResourceInfo - вид ресурса из discovery (встроенный или CRD)
+type APIResourceInfo struct {
+	Group      string   `json:"group"`
+	Version    string   `json:"version"`
+	Resource   string   `json:"resource"`
+	Kind       string   `json:"kind"`
+	Namespaced bool     `json:"namespaced"`
+	Verbs      []string `json:"verbs"`
+	ShortNames []string `json:"shortNames,omitempty"`
+}
+
+// PrinterColumn - колонка таблицы (additionalPrinterColumns CRD)
+type PrinterColumn struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	JSONPath    string `json:"jsonPath"`
+	Description string `json:"description,omitempty"`
+	Priority    int64  `json:"priority"`
+}
+
+// APIResources - предпочтительные версии всех ресурсов кластера без подресурсов.
+// Недоступные группы (например, упавший aggregated API) пропускаются.
+func (c *Cluster) APIResources() ([]APIResourceInfo, error) {
+	lists, err := c.Clientset.Discovery().ServerPreferredResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+
+	var resources []APIResourceInfo
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			resources = append(resources, APIResourceInfo{
+				Group:      gv.Group,
+				Version:    gv.Version,
+				Resource:   resource.Name,
+				Kind:       resource.Kind,
+				Namespaced: resource.Namespaced,
+				Verbs:      resource.Verbs,
+				ShortNames: resource.ShortNames,
+			})
+		}
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Group != resources[j].Group {
+			return resources[i].Group < resources[j].Group
+		}
+		return resources[i].Resource < resources[j].Resource
+	})
+	return resources, nil
+}
+
+// ResourceMapping - mapping для group/version/resource; при промахе кеш discovery сбрасывается
+func (c *Cluster) ResourceMapping(gvr schema.GroupVersionResource) (*meta.RESTMapping, error) {
+	gvk, err := c.Mapper.KindFor(gvr)
+	if meta.IsNoMatchError(err) {
+		c.Mapper.Reset()
+		gvk, err = c.Mapper.KindFor(gvr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+// PrinterColumns - additionalPrinterColumns CRD для версии ресурса; nil для встроенных ресурсов
+func (c *Cluster) PrinterColumns(ctx context.Context, gvr schema.GroupVersionResource) ([]PrinterColumn, error) {
+	if gvr.Group == "" {
+		return nil, nil
+	}
+
+	crd, err := c.Dynamic.Resource(crdResource).Get(ctx, gvr.Resource+"."+gvr.Group, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, item := range versions {
+		version, ok := item.(map[string]interface{})
+		if !ok || version["name"] != gvr.Version {
+			continue
+		}
+
+		specs, _, _ := unstructured.NestedSlice(version, "additionalPrinterColumns")
+		columns := make([]PrinterColumn, 0, len(specs))
+		for _, spec := range specs {
+			column, ok := spec.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			printer := PrinterColumn{}
+			printer.Name, _, _ = unstructured.NestedString(column, "name")
+			printer.Type, _, _ = unstructured.NestedString(column, "type")
+			printer.JSONPath, _, _ = unstructured.NestedString(column, "jsonPath")
+			printer.Description, _, _ = unstructured.NestedString(column, "description")
+			printer.Priority, _, _ = unstructured.NestedInt64(column, "priority")
+			columns = append(columns, printer)
+		}
+		return columns, nil
+	}
+	return nil, nil
+}
+
+// PrinterCells - значения колонок для объекта (как kubectl get); ошибки JSONPath дают пустую ячейку
+func PrinterCells(obj *unstructured.Unstructured, columns []PrinterColumn) []interface{} {
+	cells := make([]interface{}, len(columns))
+	for i, column := range columns {
+		parser := jsonpath.New(column.Name).AllowMissingKeys(true)
+		if err := parser.Parse(fmt.Sprintf("{%s}", column.JSONPath)); err != nil {
+			continue
+		}
+
+		results, err := parser.FindResults(obj.Object)
+		if err != nil || len(results) == 0 || len(results[0]) == 0 {
+			continue
+		}
+		if len(results[0]) == 1 {
+			cells[i] = results[0][0].Interface()
+			continue
+		}
+
+		var out bytes.Buffer
+		if err := parser.PrintResults(&out, results[0]); err == nil {
+			cells[i] = out.String()
+		}
+	}
+	return cells
+}
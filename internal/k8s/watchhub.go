@@ -0,0 +1,662 @@
+package k8s
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Типы событий WatchHub (как у watch API)
+const (
+	WatchAdded    = "ADDED"
+	WatchModified = "MODIFIED"
+	WatchDeleted  = "DELETED"
+)
+
+// WatchEvent - изменение объекта в сжатом виде.
+// ADDED несет сводку объекта целиком, MODIFIED - только изменившиеся поля сводки (null - поле исчезло).
+type WatchEvent struct {
+	Type            string                 `json:"type"`
+	Resource        string                 `json:"resource"`
+	Namespace       string                 `json:"namespace,omitempty"`
+	Name            string                 `json:"name"`
+	ResourceVersion string                 `json:"resourceVersion,omitempty"`
+	Object          map[string]interface{} `json:"object,omitempty"`
+}
+
+// watchResource - как получать информер ресурса (если его нет в ResourceCache), сводку и поля для fieldSelector
+type watchResource struct {
+	namespaced bool
+	informer   func(factory informers.SharedInformerFactory) cache.SharedIndexInformer
+	summarize  func(obj interface{}) map[string]interface{}
+	fields     func(obj interface{}) fields.Set
+}
+
+var watchResources = map[string]watchResource{
+	"pods": {
+		namespaced: true,
+		informer: func(factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+			return factory.Core().V1().Pods().Informer()
+		},
+		summarize: func(obj interface{}) map[string]interface{} {
+			pod := obj.(*corev1.Pod)
+			ready := 0
+			var restarts int32
+			for _, status := range pod.Status.ContainerStatuses {
+				if status.Ready {
+					ready++
+				}
+				restarts += status.RestartCount
+			}
+			return map[string]interface{}{
+				"status":   podDisplayStatus(pod),
+				"phase":    string(pod.Status.Phase),
+				"ready":    fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers)),
+				"restarts": restarts,
+				"ip":       pod.Status.PodIP,
+				"node":     pod.Spec.NodeName,
+				"created":  pod.CreationTimestamp.Format(time.RFC3339),
+			}
+		},
+		fields: func(obj interface{}) fields.Set {
+			pod := obj.(*corev1.Pod)
+			return fields.Set{
+				"spec.nodeName": pod.Spec.NodeName,
+				"status.phase":  string(pod.Status.Phase),
+				"status.podIP":  pod.Status.PodIP,
+			}
+		},
+	},
+	"deployments": {
+		namespaced: true,
+		informer: func(factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+			return factory.Apps().V1().Deployments().Informer()
+		},
+		summarize: func(obj interface{}) map[string]interface{} {
+			dep := obj.(*appsv1.Deployment)
+			replicas := int32(1)
+			if dep.Spec.Replicas != nil {
+				replicas = *dep.Spec.Replicas
+			}
+			images := []string{}
+			for _, container := range dep.Spec.Template.Spec.Containers {
+				images = append(images, container.Image)
+			}
+			return map[string]interface{}{
+				"replicas":  replicas,
+				"ready":     fmt.Sprintf("%d/%d", dep.Status.ReadyReplicas, replicas),
+				"updated":   dep.Status.UpdatedReplicas,
+				"available": dep.Status.AvailableReplicas,
+				"paused":    dep.Spec.Paused,
+				"images":    images,
+				"created":   dep.CreationTimestamp.Format(time.RFC3339),
+			}
+		},
+	},
+	"services": {
+		namespaced: true,
+		informer: func(factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+			return factory.Core().V1().Services().Informer()
+		},
+		summarize: func(obj interface{}) map[string]interface{} {
+			svc := obj.(*corev1.Service)
+			ports := []string{}
+			for _, port := range svc.Spec.Ports {
+				ports = append(ports, fmt.Sprintf("%d/%s", port.Port, port.Protocol))
+			}
+			external := []string{}
+			for _, ingress := range svc.Status.LoadBalancer.Ingress {
+				if ingress.IP != "" {
+					external = append(external, ingress.IP)
+				} else if ingress.Hostname != "" {
+					external = append(external, ingress.Hostname)
+				}
+			}
+			return map[string]interface{}{
+				"type":       string(svc.Spec.Type),
+				"clusterIP":  svc.Spec.ClusterIP,
+				"externalIP": external,
+				"ports":      ports,
+				"selector":   svc.Spec.Selector,
+				"created":    svc.CreationTimestamp.Format(time.RFC3339),
+			}
+		},
+		fields: func(obj interface{}) fields.Set {
+			svc := obj.(*corev1.Service)
+			return fields.Set{"spec.type": string(svc.Spec.Type)}
+		},
+	},
+	"events": {
+		namespaced: true,
+		informer: func(factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+			return factory.Core().V1().Events().Informer()
+		},
+		summarize: func(obj interface{}) map[string]interface{} {
+			event := obj.(*corev1.Event)
+			return map[string]interface{}{
+				"type":     event.Type,
+				"reason":   event.Reason,
+				"message":  event.Message,
+				"object":   event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+				"count":    EventCount(event),
+				"lastSeen": EventLastSeen(event).Format(time.RFC3339),
+				"source":   EventSource(event),
+			}
+		},
+		fields: func(obj interface{}) fields.Set {
+			event := obj.(*corev1.Event)
+			return fields.Set{
+				"involvedObject.kind":      event.InvolvedObject.Kind,
+				"involvedObject.name":      event.InvolvedObject.Name,
+				"involvedObject.namespace": event.InvolvedObject.Namespace,
+				"involvedObject.uid":       string(event.InvolvedObject.UID),
+				"reason":                   event.Reason,
+				"type":                     event.Type,
+				"source":                   EventSource(event),
+			}
+		},
+	},
+	"nodes": {
+		namespaced: false,
+		informer: func(factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+			return factory.Core().V1().Nodes().Informer()
+		},
+		summarize: func(obj interface{}) map[string]interface{} {
+			node := obj.(*corev1.Node)
+			status := "NotReady"
+			pressure := []string{}
+			for _, condition := range node.Status.Conditions {
+				switch {
+				case condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue:
+					status = "Ready"
+				case condition.Type != corev1.NodeReady && condition.Status == corev1.ConditionTrue:
+					pressure = append(pressure, string(condition.Type))
+				}
+			}
+			roles := []string{}
+			for label := range node.Labels {
+				if role, ok := strings.CutPrefix(label, "node-role.kubernetes.io/"); ok && role != "" {
+					roles = append(roles, role)
+				}
+			}
+			sort.Strings(roles)
+			internalIP := ""
+			for _, address := range node.Status.Addresses {
+				if address.Type == corev1.NodeInternalIP {
+					internalIP = address.Address
+				}
+			}
+			return map[string]interface{}{
+				"status":        status,
+				"unschedulable": node.Spec.Unschedulable,
+				"conditions":    pressure,
+				"roles":         roles,
+				"version":       node.Status.NodeInfo.KubeletVersion,
+				"internalIP":    internalIP,
+				"taints":        len(node.Spec.Taints),
+				"created":       node.CreationTimestamp.Format(time.RFC3339),
+			}
+		},
+		fields: func(obj interface{}) fields.Set {
+			node := obj.(*corev1.Node)
+			return fields.Set{"spec.unschedulable": fmt.Sprint(node.Spec.Unschedulable)}
+		},
+	},
+}
+
+// WatchResources - ресурсы, на которые можно подписаться
+func WatchResources() []string {
+	names := make([]string, 0, len(watchResources))
+	for name := range watchResources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// podDisplayStatus - статус пода как в kubectl get pods: причина ожидания/завершения контейнера или фаза
+func podDisplayStatus(pod *corev1.Pod) string {
+	if pod.DeletionTimestamp != nil {
+		return "Terminating"
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason != "" {
+			return status.State.Waiting.Reason
+		}
+		if status.State.Terminated != nil && status.State.Terminated.Reason != "" {
+			return status.State.Terminated.Reason
+		}
+	}
+	if pod.Status.Reason != "" {
+		return pod.Status.Reason
+	}
+	return string(pod.Status.Phase)
+}
+
+// WatchHub - подписки в реальном времени: одна лента на ресурс и namespace, изменения раздаются всем подписчикам.
+// Ресурсы из ResourceCache читаются из его информеров (для namespace - с фильтром), для остальных
+// хаб запускает свой информер и останавливает его с уходом последнего подписчика.
+type WatchHub struct {
+	clientset *kubernetes.Clientset
+	resources *ResourceCache
+	feeds     map[string]*watchFeed
+	mu        sync.Mutex
+}
+
+// NewWatchHub - создает хаб; информеры запускаются при первой подписке
+func NewWatchHub(clientset *kubernetes.Clientset, resources *ResourceCache) *WatchHub {
+	return &WatchHub{
+		clientset: clientset,
+		resources: resources,
+		feeds:     make(map[string]*watchFeed),
+	}
+}
+
+// watchFeed - обработчик на информере и подписчики ленты
+type watchFeed struct {
+	hub          *WatchHub
+	key          string
+	resource     string
+	namespace    string
+	spec         watchResource
+	informer     cache.SharedIndexInformer
+	registration cache.ResourceEventHandlerRegistration
+	stopChan     chan struct{} // nil, если информер общий с ResourceCache
+
+	mu          sync.RWMutex
+	subscribers map[*WatchSubscription]struct{}
+}
+
+// WatchFeedInfo - информер хаба и число его подписчиков
+type WatchFeedInfo struct {
+	Resource    string `json:"resource"`
+	Namespace   string `json:"namespace,omitempty"`
+	Subscribers int    `json:"subscribers"`
+	Synced      bool   `json:"synced"`
+}
+
+// Feeds - активные информеры хаба
+func (h *WatchHub) Feeds() []WatchFeedInfo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	result := make([]WatchFeedInfo, 0, len(h.feeds))
+	for _, feed := range h.feeds {
+		feed.mu.RLock()
+		result = append(result, WatchFeedInfo{
+			Resource:    feed.resource,
+			Namespace:   feed.namespace,
+			Subscribers: len(feed.subscribers),
+			Synced:      feed.synced(),
+		})
+		feed.mu.RUnlock()
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Resource != result[j].Resource {
+			return result[i].Resource < result[j].Resource
+		}
+		return result[i].Namespace < result[j].Namespace
+	})
+	return result
+}
+
+// Subscribe - подписка на изменения ресурса в namespace ("" - все namespace).
+// Сначала подписчик получает ADDED для всех подходящих объектов, затем изменения.
+func (h *WatchHub) Subscribe(resource, namespace string, labelSelector labels.Selector, fieldSelector fields.Selector) (*WatchSubscription, error) {
+	spec, ok := watchResources[resource]
+	if !ok {
+		return nil, fmt.Errorf("unsupported resource %q, supported: %s", resource, strings.Join(WatchResources(), ", "))
+	}
+	if !spec.namespaced {
+		namespace = ""
+	}
+	if labelSelector == nil {
+		labelSelector = labels.Everything()
+	}
+	if fieldSelector == nil {
+		fieldSelector = fields.Everything()
+	}
+
+	sub := &WatchSubscription{
+		labels:  labelSelector,
+		fields:  fieldSelector,
+		pending: make(map[string]*watchState),
+		sent:    make(map[string]watchState),
+		ready:   make(chan struct{}, 1),
+		synced:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	key := resource + "/" + namespace
+	feed, exists := h.feeds[key]
+	if !exists {
+		feed = h.startFeed(key, resource, namespace, spec)
+		h.feeds[key] = feed
+	}
+	sub.feed = feed
+
+	feed.mu.Lock()
+	feed.subscribers[sub] = struct{}{}
+	feed.mu.Unlock()
+	h.mu.Unlock()
+
+	// Начальное состояние - из кэша информера после синхронизации
+	go func() {
+		if !cache.WaitForCacheSync(sub.done, feed.synced) {
+			return
+		}
+		for _, obj := range feed.list() {
+			sub.offer(feed.state(obj))
+		}
+		close(sub.synced)
+		sub.notify()
+	}()
+
+	return sub, nil
+}
+
+func (h *WatchHub) startFeed(key, resource, namespace string, spec watchResource) *watchFeed {
+	feed := &watchFeed{
+		hub:         h,
+		key:         key,
+		resource:    resource,
+		namespace:   namespace,
+		spec:        spec,
+		subscribers: make(map[*WatchSubscription]struct{}),
+	}
+
+	if h.resources != nil {
+		feed.informer = h.resources.sharedInformer(resource)
+	}
+	if feed.informer == nil {
+		options := []informers.SharedInformerOption{informers.WithTransform(stripManagedFields)}
+		if namespace != "" {
+			options = append(options, informers.WithNamespace(namespace))
+		}
+		factory := informers.NewSharedInformerFactoryWithOptions(h.clientset, cacheResync, options...)
+		feed.informer = spec.informer(factory)
+		feed.stopChan = make(chan struct{})
+	}
+
+	// Ошибка возможна только у остановленного информера; лента тогда просто не получит событий
+	feed.registration, _ = feed.informer.AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: feed.inNamespace,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { feed.broadcast(obj, false) },
+			UpdateFunc: func(_, obj interface{}) { feed.broadcast(obj, false) },
+			DeleteFunc: func(obj interface{}) { feed.broadcast(obj, true) },
+		},
+	})
+
+	log.Printf("👁️  Starting watch feed %s", key)
+	if feed.stopChan != nil {
+		go feed.informer.Run(feed.stopChan)
+	}
+	return feed
+}
+
+// stop - снимает обработчик ленты; собственный информер останавливается
+func (f *watchFeed) stop() {
+	if f.registration != nil {
+		_ = f.informer.RemoveEventHandler(f.registration)
+	}
+	if f.stopChan != nil {
+		close(f.stopChan)
+	}
+}
+
+// synced - обработчик ленты получил начальный список объектов
+func (f *watchFeed) synced() bool {
+	return f.registration != nil && f.registration.HasSynced()
+}
+
+// inNamespace - объект относится к namespace ленты (общий информер кэша видит весь кластер)
+func (f *watchFeed) inNamespace(obj interface{}) bool {
+	if f.namespace == "" {
+		return true
+	}
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	accessor, err := meta.Accessor(obj)
+	return err == nil && accessor.GetNamespace() == f.namespace
+}
+
+// list - текущие объекты ленты из кэша информера
+func (f *watchFeed) list() []interface{} {
+	if f.namespace == "" {
+		return f.informer.GetStore().List()
+	}
+	objects, err := f.informer.GetIndexer().ByIndex(cache.NamespaceIndex, f.namespace)
+	if err != nil {
+		return nil
+	}
+	return objects
+}
+
+// unsubscribe - удаляет подписчика; последний уход останавливает информер
+func (h *WatchHub) unsubscribe(sub *WatchSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	feed := sub.feed
+	feed.mu.Lock()
+	delete(feed.subscribers, sub)
+	remaining := len(feed.subscribers)
+	feed.mu.Unlock()
+
+	if remaining == 0 && h.feeds[feed.key] == feed {
+		delete(h.feeds, feed.key)
+		feed.stop()
+		log.Printf("👁️  Stopped watch feed %s", feed.key)
+	}
+}
+
+// watchState - сводка объекта на момент события
+type watchState struct {
+	key             string
+	namespace       string
+	name            string
+	resourceVersion string
+	labels          labels.Set
+	fields          fields.Set
+	object          map[string]interface{}
+	deleted         bool
+}
+
+func (f *watchFeed) state(obj interface{}) *watchState {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil
+	}
+
+	state := &watchState{
+		namespace:       accessor.GetNamespace(),
+		name:            accessor.GetName(),
+		resourceVersion: accessor.GetResourceVersion(),
+		labels:          labels.Set(accessor.GetLabels()),
+		fields: fields.Set{
+			"metadata.name":      accessor.GetName(),
+			"metadata.namespace": accessor.GetNamespace(),
+		},
+		object: f.spec.summarize(obj),
+	}
+	state.key = state.name
+	if state.namespace != "" {
+		state.key = state.namespace + "/" + state.name
+	}
+	if f.spec.fields != nil {
+		for k, v := range f.spec.fields(obj) {
+			state.fields[k] = v
+		}
+	}
+	return state
+}
+
+func (f *watchFeed) broadcast(obj interface{}, deleted bool) {
+	state := f.state(obj)
+	if state == nil {
+		return
+	}
+	state.deleted = deleted
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for sub := range f.subscribers {
+		sub.offer(state)
+	}
+}
+
+// WatchSubscription - подписка на WatchHub. Изменения одного объекта между чтениями
+// схлопываются в одно событие, поэтому медленный клиент не копит очередь.
+type WatchSubscription struct {
+	feed   *watchFeed
+	labels labels.Selector
+	fields fields.Selector
+
+	mu      sync.Mutex
+	pending map[string]*watchState // последнее состояние объекта с прошлого Drain
+	order   []string
+	sent    map[string]watchState // что уже известно клиенту
+
+	ready     chan struct{}
+	synced    chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Resource, Namespace - на что оформлена подписка
+func (s *WatchSubscription) Resource() string  { return s.feed.resource }
+func (s *WatchSubscription) Namespace() string { return s.feed.namespace }
+
+// Ready - сигнал, что есть события для Drain
+func (s *WatchSubscription) Ready() <-chan struct{} { return s.ready }
+
+// Synced - закрывается после выдачи начального состояния
+func (s *WatchSubscription) Synced() <-chan struct{} { return s.synced }
+
+// Close - отписка; повторный вызов безопасен
+func (s *WatchSubscription) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.feed.hub.unsubscribe(s)
+	})
+}
+
+func (s *WatchSubscription) notify() {
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// offer - новое состояние объекта; объект, переставший подходить под селекторы, для клиента удаляется
+func (s *WatchSubscription) offer(state *watchState) {
+	if state == nil {
+		return
+	}
+
+	matches := !state.deleted && s.labels.Matches(state.labels) && s.fields.Matches(state.fields)
+
+	s.mu.Lock()
+	_, known := s.sent[state.key]
+	_, queued := s.pending[state.key]
+	if !matches && !known && !queued {
+		s.mu.Unlock()
+		return
+	}
+	if !queued {
+		s.order = append(s.order, state.key)
+	}
+	if matches {
+		s.pending[state.key] = state
+	} else {
+		s.pending[state.key] = &watchState{key: state.key, namespace: state.namespace, name: state.name,
+			resourceVersion: state.resourceVersion, deleted: true}
+	}
+	s.mu.Unlock()
+
+	s.notify()
+}
+
+// Drain - накопленные события: полная сводка для новых объектов, разница для измененных
+func (s *WatchSubscription) Drain() []WatchEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []WatchEvent
+	for _, key := range s.order {
+		state := s.pending[key]
+		prev, known := s.sent[key]
+
+		event := WatchEvent{
+			Resource:        s.feed.resource,
+			Namespace:       state.namespace,
+			Name:            state.name,
+			ResourceVersion: state.resourceVersion,
+		}
+
+		switch {
+		case state.deleted:
+			if !known {
+				continue
+			}
+			event.Type = WatchDeleted
+			delete(s.sent, key)
+
+		case !known:
+			event.Type = WatchAdded
+			event.Object = state.object
+			s.sent[key] = *state
+
+		default:
+			diff := diffSummary(prev.object, state.object)
+			s.sent[key] = *state
+			if len(diff) == 0 {
+				continue
+			}
+			event.Type = WatchModified
+			event.Object = diff
+		}
+
+		events = append(events, event)
+	}
+
+	s.pending = make(map[string]*watchState)
+	s.order = s.order[:0]
+	return events
+}
+
+// diffSummary - поля сводки, изменившиеся между from и to; удаленные поля - nil
+func diffSummary(from, to map[string]interface{}) map[string]interface{} {
+	diff := make(map[string]interface{})
+	for k, v := range to {
+		if old, ok := from[k]; !ok || !reflect.DeepEqual(old, v) {
+			diff[k] = v
+		}
+	}
+	for k := range from {
+		if _, ok := to[k]; !ok {
+			diff[k] = nil
+		}
+	}
+	return diff
+}
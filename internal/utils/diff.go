@@ -0,0 +1,280 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// DiffLine - строка результата сравнения: op = " ", "-" или "+"
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// DiffLines - построчное сравнение двух текстов. Общие начало и конец отрезаются сразу,
+// середина сравнивается SequenceMatcher из go-difflib (память линейна по размеру текстов).
+func DiffLines(from, to string) []DiffLine {
+	a := splitLines(from)
+	b := splitLines(to)
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	result := make([]DiffLine, 0, len(a)+len(b)-prefix-suffix)
+	for _, line := range a[:prefix] {
+		result = append(result, DiffLine{Op: " ", Text: line})
+	}
+
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+	for _, code := range difflib.NewMatcher(midA, midB).GetOpCodes() {
+		switch code.Tag {
+		case 'e':
+			for _, line := range midA[code.I1:code.I2] {
+				result = append(result, DiffLine{Op: " ", Text: line})
+			}
+		default:
+			// 'r' - замена, 'd' - удаление, 'i' - вставка
+			for _, line := range midA[code.I1:code.I2] {
+				result = append(result, DiffLine{Op: "-", Text: line})
+			}
+			for _, line := range midB[code.J1:code.J2] {
+				result = append(result, DiffLine{Op: "+", Text: line})
+			}
+		}
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		result = append(result, DiffLine{Op: " ", Text: line})
+	}
+
+	return result
+}
+
+// UnifiedDiff - diff в формате unified с 3 строками контекста; пустая строка, если отличий нет
+func UnifiedDiff(from, to, fromName, toName string) string {
+	lines := DiffLines(from, to)
+
+	const context = 3
+	var out strings.Builder
+
+	for start := 0; start < len(lines); {
+		// Ищем следующее изменение
+		for start < len(lines) && lines[start].Op == " " {
+			start++
+		}
+		if start >= len(lines) {
+			break
+		}
+
+		// Расширяем ханк, пока изменения ближе 2*context строк
+		hunkStart := start - context
+		if hunkStart < 0 {
+			hunkStart = 0
+		}
+		end := start
+		for end < len(lines) {
+			if lines[end].Op != " " {
+				end++
+				continue
+			}
+			next := end
+			for next < len(lines) && lines[next].Op == " " {
+				next++
+			}
+			if next < len(lines) && next-end <= 2*context {
+				end = next
+				continue
+			}
+			break
+		}
+		hunkEnd := end + context
+		if hunkEnd > len(lines) {
+			hunkEnd = len(lines)
+		}
+
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+		}
+
+		// Номера строк для заголовка ханка
+		fromLine, toLine := 1, 1
+		for _, line := range lines[:hunkStart] {
+			if line.Op != "+" {
+				fromLine++
+			}
+			if line.Op != "-" {
+				toLine++
+			}
+		}
+		fromCount, toCount := 0, 0
+		for _, line := range lines[hunkStart:hunkEnd] {
+			if line.Op != "+" {
+				fromCount++
+			}
+			if line.Op != "-" {
+				toCount++
+			}
+		}
+
+		// Пустая сторона ханка нумеруется с 0 (@@ -0,0 +1,N @@), иначе patch его не примет
+		if fromCount == 0 {
+			fromLine--
+		}
+		if toCount == 0 {
+			toLine--
+		}
+
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", fromLine, fromCount, toLine, toCount)
+		for _, line := range lines[hunkStart:hunkEnd] {
+			out.WriteString(line.Op + line.Text + "\n")
+		}
+
+		start = hunkEnd
+	}
+
+	return out.String()
+}
+
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// FieldChange - изменение одного поля объекта: op = "added", "removed" или "changed"
+type FieldChange struct {
+	Path string      `json:"path"`
+	Op   string      `json:"op"`
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// DiffObjects - структурное сравнение двух объектов (как из JSON).
+// Списки элементов с полем name (containers, env, ports) сравниваются по имени, остальные - по индексу.
+func DiffObjects(from, to map[string]interface{}) []FieldChange {
+	changes := []FieldChange{}
+	diffValues("", from, to, &changes)
+	return changes
+}
+
+func diffValues(path string, from, to interface{}, changes *[]FieldChange) {
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+	if fromIsMap && toIsMap {
+		keys := make([]string, 0, len(fromMap)+len(toMap))
+		for key := range fromMap {
+			keys = append(keys, key)
+		}
+		for key := range toMap {
+			if _, ok := fromMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			fromValue, inFrom := fromMap[key]
+			toValue, inTo := toMap[key]
+			child := fieldPath(path, key)
+			switch {
+			case !inFrom:
+				*changes = append(*changes, FieldChange{Path: child, Op: "added", To: toValue})
+			case !inTo:
+				*changes = append(*changes, FieldChange{Path: child, Op: "removed", From: fromValue})
+			default:
+				diffValues(child, fromValue, toValue, changes)
+			}
+		}
+		return
+	}
+
+	fromList, fromIsList := from.([]interface{})
+	toList, toIsList := to.([]interface{})
+	if fromIsList && toIsList {
+		if fromNames, ok := listNames(fromList); ok {
+			if toNames, ok := listNames(toList); ok {
+				diffNamedLists(path, fromList, toList, fromNames, toNames, changes)
+				return
+			}
+		}
+		if len(fromList) == len(toList) {
+			for i := range fromList {
+				diffValues(fmt.Sprintf("%s[%d]", path, i), fromList[i], toList[i], changes)
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, FieldChange{Path: path, Op: "changed", From: from, To: to})
+	}
+}
+
+// diffNamedLists - сравнение списков по полю name: containers[name=app].image
+func diffNamedLists(path string, fromList, toList []interface{}, fromNames, toNames []string, changes *[]FieldChange) {
+	toIndex := make(map[string]int, len(toNames))
+	for i, name := range toNames {
+		toIndex[name] = i
+	}
+	fromIndex := make(map[string]int, len(fromNames))
+	for i, name := range fromNames {
+		fromIndex[name] = i
+		child := fmt.Sprintf("%s[name=%s]", path, name)
+		if j, ok := toIndex[name]; ok {
+			diffValues(child, fromList[i], toList[j], changes)
+		} else {
+			*changes = append(*changes, FieldChange{Path: child, Op: "removed", From: fromList[i]})
+		}
+	}
+	for j, name := range toNames {
+		if _, ok := fromIndex[name]; !ok {
+			*changes = append(*changes, FieldChange{Path: fmt.Sprintf("%s[name=%s]", path, name), Op: "added", To: toList[j]})
+		}
+	}
+}
+
+// listNames - имена элементов, если все элементы - объекты с уникальным строковым name
+func listNames(list []interface{}) ([]string, bool) {
+	if len(list) == 0 {
+		return nil, false
+	}
+	names := make([]string, 0, len(list))
+	seen := make(map[string]bool, len(list))
+	for _, item := range list {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, ok := obj["name"].(string)
+		if !ok || seen[name] {
+			return nil, false
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names, true
+}
+
+// fieldPath - путь к полю; ключи с точками и слэшами (аннотации) в скобках
+func fieldPath(path, key string) string {
+	if strings.ContainsAny(key, "./") {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
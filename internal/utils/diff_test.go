@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func numberedLines(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	return lines
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want []DiffLine
+	}{
+		{
+			name: "equal",
+			from: "a\nb\n",
+			to:   "a\nb\n",
+			want: []DiffLine{{" ", "a"}, {" ", "b"}},
+		},
+		{
+			name: "replace in the middle",
+			from: "a\nb\nc\n",
+			to:   "a\nX\nc\n",
+			want: []DiffLine{{" ", "a"}, {"-", "b"}, {"+", "X"}, {" ", "c"}},
+		},
+		{
+			name: "insert and delete",
+			from: "a\nb\nc\n",
+			to:   "b\nc\nd\n",
+			want: []DiffLine{{"-", "a"}, {" ", "b"}, {" ", "c"}, {"+", "d"}},
+		},
+		{
+			name: "from empty",
+			from: "",
+			to:   "a\n",
+			want: []DiffLine{{"+", "a"}},
+		},
+		{
+			name: "to empty",
+			from: "a\n",
+			to:   "",
+			want: []DiffLine{{"-", "a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffLines(tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffLines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	base := numberedLines(20)
+	withChanges := func(indexes ...int) string {
+		lines := append([]string(nil), base...)
+		for _, i := range indexes {
+			lines[i] = "changed"
+		}
+		return strings.Join(lines, "\n") + "\n"
+	}
+	from := strings.Join(base, "\n") + "\n"
+
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		headers []string
+	}{
+		{
+			name: "no changes",
+			from: from,
+			to:   from,
+		},
+		{
+			name:    "single change",
+			from:    from,
+			to:      withChanges(10),
+			headers: []string{"@@ -8,7 +8,7 @@"},
+		},
+		{
+			// 6 одинаковых строк между изменениями (2*context) - один ханк
+			name:    "merged at 2x context",
+			from:    from,
+			to:      withChanges(5, 12),
+			headers: []string{"@@ -3,14 +3,14 @@"},
+		},
+		{
+			// 7 строк - уже два ханка
+			name:    "split beyond 2x context",
+			from:    from,
+			to:      withChanges(5, 13),
+			headers: []string{"@@ -3,7 +3,7 @@", "@@ -11,7 +11,7 @@"},
+		},
+		{
+			name:    "new file",
+			from:    "",
+			to:      "a\nb\n",
+			headers: []string{"@@ -0,0 +1,2 @@"},
+		},
+		{
+			name:    "deleted file",
+			from:    "a\nb\n",
+			to:      "",
+			headers: []string{"@@ -1,2 +0,0 @@"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := UnifiedDiff(tt.from, tt.to, "live", "edited")
+			if len(tt.headers) == 0 {
+				if diff != "" {
+					t.Fatalf("UnifiedDiff() = %q, want empty", diff)
+				}
+				return
+			}
+			if !strings.HasPrefix(diff, "--- live\n+++ edited\n") {
+				t.Errorf("missing file headers: %q", diff)
+			}
+
+			var headers []string
+			for _, line := range strings.Split(diff, "\n") {
+				if strings.HasPrefix(line, "@@") {
+					headers = append(headers, line)
+				}
+			}
+			if !reflect.DeepEqual(headers, tt.headers) {
+				t.Errorf("hunks = %v, want %v", headers, tt.headers)
+			}
+		})
+	}
+}
+
+func TestDiffObjects(t *testing.T) {
+	container := func(name, image string) map[string]interface{} {
+		return map[string]interface{}{"name": name, "image": image}
+	}
+
+	tests := []struct {
+		name string
+		from map[string]interface{}
+		to   map[string]interface{}
+		want []FieldChange
+	}{
+		{
+			name: "equal",
+			from: map[string]interface{}{"replicas": int64(1)},
+			to:   map[string]interface{}{"replicas": int64(1)},
+			want: []FieldChange{},
+		},
+		{
+			name: "added removed changed",
+			from: map[string]interface{}{"a": "1", "b": "2"},
+			to:   map[string]interface{}{"b": "3", "c": "4"},
+			want: []FieldChange{
+				{Path: "a", Op: "removed", From: "1"},
+				{Path: "b", Op: "changed", From: "2", To: "3"},
+				{Path: "c", Op: "added", To: "4"},
+			},
+		},
+		{
+			name: "zero values are kept",
+			from: map[string]interface{}{"paused": true, "replicas": int64(1)},
+			to:   map[string]interface{}{"paused": false, "replicas": int64(0)},
+			want: []FieldChange{
+				{Path: "paused", Op: "changed", From: true, To: false},
+				{Path: "replicas", Op: "changed", From: int64(1), To: int64(0)},
+			},
+		},
+		{
+			name: "named list matched by name, not index",
+			from: map[string]interface{}{"containers": []interface{}{container("app", "v1"), container("sidecar", "s1")}},
+			to:   map[string]interface{}{"containers": []interface{}{container("sidecar", "s1"), container("app", "v2")}},
+			want: []FieldChange{
+				{Path: "containers[name=app].image", Op: "changed", From: "v1", To: "v2"},
+			},
+		},
+		{
+			name: "named list add and remove",
+			from: map[string]interface{}{"env": []interface{}{container("A", ""), container("B", "")}},
+			to:   map[string]interface{}{"env": []interface{}{container("B", ""), container("C", "")}},
+			want: []FieldChange{
+				{Path: "env[name=A]", Op: "removed", From: container("A", "")},
+				{Path: "env[name=C]", Op: "added", To: container("C", "")},
+			},
+		},
+		{
+			name: "duplicate names fall back to index",
+			from: map[string]interface{}{"items": []interface{}{container("x", "1"), container("x", "2")}},
+			to:   map[string]interface{}{"items": []interface{}{container("x", "1"), container("x", "3")}},
+			want: []FieldChange{
+				{Path: "items[1].image", Op: "changed", From: "2", To: "3"},
+			},
+		},
+		{
+			name: "lists of different length are replaced",
+			from: map[string]interface{}{"args": []interface{}{"a"}},
+			to:   map[string]interface{}{"args": []interface{}{"a", "b"}},
+			want: []FieldChange{
+				{Path: "args", Op: "changed", From: []interface{}{"a"}, To: []interface{}{"a", "b"}},
+			},
+		},
+		{
+			name: "keys with dots and slashes are quoted",
+			from: map[string]interface{}{"metadata": map[string]interface{}{"annotations": map[string]interface{}{}}},
+			to: map[string]interface{}{"metadata": map[string]interface{}{"annotations": map[string]interface{}{
+				"example.com/owner": "team",
+			}}},
+			want: []FieldChange{
+				{Path: `metadata.annotations["example.com/owner"]`, Op: "added", To: "team"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffObjects(tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffObjects() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -7,34 +7,21 @@ import (
 
 	"k8s-manager/api"
 	// "k8s-manager/internal/config"
+	"k8s-manager/internal/k8s"
 
 	"github.com/gin-gonic/gin"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
-	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
 func main() {
-	// Настройка клиента Kubernetes
+	// Настройка клиентов Kubernetes для всех контекстов kubeconfig
 	kubeconfig := os.Getenv("KUBECONFIG")
 	if kubeconfig == "" {
 		kubeconfig = os.Getenv("HOME") + "/.kube/config"
 	}
 
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	clusters, err := k8s.LoadClusterRegistry(kubeconfig)
 	if err != nil {
-		log.Fatalf("Failed to build kubeconfig: %v", err)
-	}
-
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		log.Fatalf("Failed to create Kubernetes client: %v", err)
-	}
-
-	metricsClient, err := metricsv.NewForConfig(config)
-	if err != nil {
-		log.Printf("Warning: Failed to create metrics client: %v", err)
-		metricsClient = nil
+		log.Fatalf("Failed to load clusters: %v", err)
 	}
 
 	// Настройка Gin
@@ -53,7 +40,7 @@ func main() {
 	r.StaticFile("/apple-touch-icon-precomposed.png", "./static/apple-touch-icon-precomposed.png")
 
 	// Настройка роутов
-	api.SetupRoutes(r, clientset, metricsClient)
+	api.SetupRoutes(r, clusters)
 
 	// Запуск сервера
 	log.Println("Starting K8s Manager on http://localhost:8080")
@@ -911,7 +911,7 @@ async function confirmScale() {
     try {
         console.log(`Scaling ${appName} in ${namespace} to ${replicas} replicas`);
         
-        const response = await fetch(`/api/scale/${namespace}/${appName}?replicas=${replicas}`, {
+        const response = await fetch(`${appActionUrl('scale', appName, namespace)}?replicas=${replicas}`, {
             method: 'POST',
             headers: {
                 'Content-Type': 'application/json'
@@ -949,11 +949,24 @@ async function confirmScale() {
     }
 }
 
+// URL действия с учетом типа приложения (Deployment / StatefulSet)
+function appActionUrl(action, appName, namespace) {
+    const app = AppState.applications.find(a => a.name === appName && a.namespace === namespace);
+    if (app && app.type === 'StatefulSet') {
+        return action === 'delete'
+            ? `/api/statefulset/${namespace}/${appName}`
+            : `/api/statefulset/${action}/${namespace}/${appName}`;
+    }
+    return action === 'delete'
+        ? `/api/deployment/${namespace}/${appName}`
+        : `/api/${action}/${namespace}/${appName}`;
+}
+
 async function restartApp(appName, namespace) {
     if (!confirm(`Are you sure you want to restart "${appName}"?`)) return;
     
     try {
-        const response = await fetch(`/api/restart/${namespace}/${appName}`, {
+        const response = await fetch(appActionUrl('restart', appName, namespace), {
             method: 'POST'
         });
         
@@ -976,7 +989,7 @@ async function deleteApp(appName, namespace) {
     if (!confirm(`Are you sure you want to delete "${appName}"?\nThis action cannot be undone.`)) return;
     
     try {
-        const response = await fetch(`/api/deployment/${namespace}/${appName}`, {
+        const response = await fetch(appActionUrl('delete', appName, namespace), {
             method: 'DELETE'
         });
         
@@ -0,0 +1,606 @@
+// Текущий выбранный namespace
+let currentNamespace = 'market';
+let currentStatefulSet = null;
+let allStatefulSets = [];
+
+// Инициализация при загрузке страницы
+document.addEventListener('DOMContentLoaded', function() {
+    loadStatefulSets();
+    setupEventListeners();
+});
+
+// Настройка слушателей событий
+function setupEventListeners() {
+    // Выбор namespace
+    document.getElementById('namespace-select').addEventListener('change', function() {
+        currentNamespace = this.value;
+        document.getElementById('current-namespace').textContent = currentNamespace;
+        loadStatefulSets();
+    });
+
+    // Поиск
+    document.getElementById('search-statefulsets').addEventListener('input', debounce(filterStatefulSets, 300));
+
+    // Кнопка обновления
+    document.getElementById('refresh-btn').addEventListener('click', loadStatefulSets);
+}
+
+// Загрузка списка StatefulSet'ов (пустой namespace - все namespace'ы)
+async function loadStatefulSets() {
+    showLoading(true);
+
+    try {
+        const namespace = currentNamespace === 'all' ? '' : currentNamespace;
+        const response = await fetch(`/api/statefulsets?namespace=${encodeURIComponent(namespace)}`);
+        if (!response.ok) {
+            throw new Error(`HTTP ${response.status}`);
+        }
+
+        const data = await response.json();
+
+        // data.statefulsets может быть null, если StatefulSet'ов нет
+        allStatefulSets = data.statefulsets || [];
+
+        updateStats(allStatefulSets);
+        renderStatefulSetsTable(allStatefulSets);
+        updateLastUpdated();
+
+        // Открытое окно с репликами показывает свежие данные
+        if (currentStatefulSet && document.getElementById('ordinalsModal').classList.contains('show')) {
+            renderOrdinals();
+        }
+    } catch (error) {
+        showError(`Failed to load statefulsets: ${error.message}`);
+        document.getElementById('statefulsets-table-body').innerHTML = `
+            <tr>
+                <td colspan="9" class="text-center py-5">
+                    <i class="fas fa-exclamation-triangle fa-2x text-danger mb-3"></i>
+                    <p class="text-muted">${escapeHtml(error.message)}</p>
+                </td>
+            </tr>
+        `;
+    } finally {
+        showLoading(false);
+    }
+}
+
+// Статистика по всем StatefulSet'ам
+function updateStats(statefulSets) {
+    let ready = 0;
+    let replicas = 0;
+    let updating = 0;
+    let boundPVCs = 0;
+
+    statefulSets.forEach(sts => {
+        replicas += sts.replicas || 0;
+        if (getStatus(sts) === 'ready') ready++;
+        if (sts.current_revision !== sts.update_revision) updating++;
+        (sts.ordinals || []).forEach(ordinal => {
+            boundPVCs += (ordinal.volumes || []).filter(v => v.status === 'Bound').length;
+        });
+    });
+
+    document.getElementById('stats-count').textContent = statefulSets.length;
+    document.getElementById('ready-count').textContent = ready;
+    document.getElementById('replicas-count').textContent = replicas;
+    document.getElementById('updating-count').textContent = updating;
+    document.getElementById('pvc-count').textContent = boundPVCs;
+}
+
+// Статус StatefulSet'а по готовым репликам
+function getStatus(sts) {
+    const readyCount = sts.ready_count || 0;
+    const totalCount = sts.total_count || 0;
+    if (readyCount === totalCount) return 'ready';
+    if (readyCount > 0) return 'progressing';
+    return 'not-ready';
+}
+
+// Отрисовка таблицы StatefulSet'ов
+function renderStatefulSetsTable(statefulSets) {
+    const tbody = document.getElementById('statefulsets-table-body');
+    const searchTerm = document.getElementById('search-statefulsets').value.toLowerCase();
+
+    const filtered = statefulSets.filter(sts => !searchTerm || sts.name.toLowerCase().includes(searchTerm));
+
+    if (filtered.length === 0) {
+        const message = statefulSets.length === 0
+            ? 'No statefulsets in this namespace'
+            : 'No statefulsets match the current filters';
+        tbody.innerHTML = `
+            <tr>
+                <td colspan="9" class="text-center py-5">
+                    <i class="fas fa-search fa-2x text-muted mb-3"></i>
+                    <p class="text-muted">${message}</p>
+                </td>
+            </tr>
+        `;
+        return;
+    }
+
+    let html = '';
+    filtered.forEach(sts => {
+        const status = getStatus(sts);
+        const statusClass = `badge-${status}`;
+        const readyCount = sts.ready_count || 0;
+        const totalCount = sts.total_count || 0;
+        const readyPercentage = totalCount > 0 ? Math.round((readyCount / totalCount) * 100) : 0;
+        const rolling = sts.strategy !== 'OnDelete';
+
+        html += `
+            <tr>
+                <td>
+                    <div class="d-flex align-items-center">
+                        <i class="fas fa-database me-2 text-primary"></i>
+                        <strong>${highlightSearch(sts.name, searchTerm)}</strong>
+                    </div>
+                    ${sts.service_name ? `<small class="text-muted">svc: ${escapeHtml(sts.service_name)}</small>` : ''}
+                </td>
+                <td><span class="badge bg-secondary">${sts.namespace}</span></td>
+                <td>
+                    <span class="badge-status ${statusClass}">
+                        <i class="fas ${getStatusIcon(status)} me-1"></i>
+                        ${status.replace('-', ' ').toUpperCase()}
+                    </span>
+                </td>
+                <td>
+                    <div>
+                        <span class="badge ${readyCount === totalCount ? 'bg-success' : 'bg-warning'}">${sts.ready}</span>
+                        <div class="replica-progress">
+                            <div class="progress-bar bg-${readyCount === totalCount ? 'success' : 'warning'}"
+                                 style="width: ${readyPercentage}%"></div>
+                        </div>
+                    </div>
+                </td>
+                <td>
+                    <span class="badge ${sts.updated_replicas === totalCount ? 'bg-success' : 'bg-warning'}">
+                        ${sts.updated_replicas || 0}/${totalCount}
+                    </span>
+                </td>
+                <td>
+                    <span class="badge ${rolling ? 'bg-info' : 'bg-secondary'}">${sts.strategy || 'RollingUpdate'}</span>
+                </td>
+                <td>${rolling ? `<span class="badge ${sts.partition > 0 ? 'bg-warning' : 'bg-light text-dark'}">${sts.partition}</span>` : '-'}</td>
+                <td><small class="text-muted">${sts.age}</small></td>
+                <td>
+                    <div class="btn-group" role="group">
+                        <button class="btn btn-action btn-outline-primary btn-sm"
+                                onclick="showOrdinals('${sts.namespace}', '${sts.name}')"
+                                title="Pods, PVCs and partition">
+                            <i class="fas fa-cubes"></i>
+                        </button>
+                        <button class="btn btn-action btn-outline-warning btn-sm"
+                                onclick="showRestartModal('${sts.namespace}', '${sts.name}')"
+                                title="Restart">
+                            <i class="fas fa-redo"></i>
+                        </button>
+                        <button class="btn btn-action btn-outline-info btn-sm"
+                                onclick="showConfig('${sts.namespace}', '${sts.name}')"
+                                title="YAML">
+                            <i class="fas fa-code"></i>
+                        </button>
+                        <button class="btn btn-action btn-outline-danger btn-sm"
+                                onclick="showDeleteModal('${sts.namespace}', '${sts.name}')"
+                                title="Delete">
+                            <i class="fas fa-trash"></i>
+                        </button>
+                    </div>
+                </td>
+            </tr>
+        `;
+    });
+
+    tbody.innerHTML = html;
+}
+
+// Найти StatefulSet из последней загрузки
+function findStatefulSet(namespace, name) {
+    return allStatefulSets.find(sts => sts.namespace === namespace && sts.name === name);
+}
+
+// Показать реплики по ordinal: поды, PVC, partition
+function showOrdinals(namespace, name) {
+    currentStatefulSet = { namespace, name };
+
+    const sts = findStatefulSet(namespace, name);
+    document.getElementById('ordinals-statefulset-name').textContent = name;
+    document.getElementById('scale-replicas').value = sts ? sts.replicas : 0;
+    document.getElementById('partition-input').value = sts ? sts.partition : 0;
+
+    renderOrdinals();
+    const modal = new bootstrap.Modal(document.getElementById('ordinalsModal'));
+    modal.show();
+}
+
+// Отрисовка реплик текущего StatefulSet'а
+function renderOrdinals() {
+    const sts = findStatefulSet(currentStatefulSet.namespace, currentStatefulSet.name);
+    const tbody = document.getElementById('ordinals-body');
+
+    if (!sts) {
+        tbody.innerHTML = `
+            <tr><td colspan="9" class="text-center text-muted py-3">StatefulSet not found</td></tr>
+        `;
+        return;
+    }
+
+    const rolling = sts.strategy !== 'OnDelete';
+    document.getElementById('ordinals-replicas').textContent = sts.replicas;
+    document.getElementById('ordinals-partition').textContent = rolling ? sts.partition : 'OnDelete';
+    document.getElementById('partition-input').disabled = !rolling;
+    document.getElementById('partition-apply-btn').disabled = !rolling;
+    document.getElementById('current-revision').textContent = sts.current_revision || '-';
+    document.getElementById('update-revision').textContent = sts.update_revision || '-';
+
+    const ordinals = sts.ordinals || [];
+    if (ordinals.length === 0) {
+        tbody.innerHTML = `
+            <tr><td colspan="9" class="text-center text-muted py-3">Scaled to zero</td></tr>
+        `;
+        return;
+    }
+
+    let html = '';
+    ordinals.forEach(ordinal => {
+        const pod = ordinal.pod || {};
+        // Реплики ниже partition остаются на текущей ревизии
+        const held = rolling && ordinal.ordinal < sts.partition;
+
+        let revision = '-';
+        if (pod.revision) {
+            const updated = pod.revision === sts.update_revision;
+            revision = `<span class="badge ${updated ? 'bg-success' : 'bg-secondary'}" title="${pod.revision}">
+                ${updated ? 'update' : 'current'}
+            </span>`;
+        }
+
+        const volumes = (ordinal.volumes || []).map(volume => `
+            <div>
+                <span class="badge ${volume.status === 'Bound' ? 'bg-success' : volume.status === 'Missing' ? 'bg-danger' : 'bg-warning'}">
+                    ${volume.status}
+                </span>
+                <small title="${volume.volume || ''}">${volume.pvc}${volume.capacity ? ` (${volume.capacity})` : ''}</small>
+            </div>
+        `).join('') || '<small class="text-muted">none</small>';
+
+        html += `
+            <tr class="pod-row ${getPodStatusClass(pod.status)}">
+                <td>
+                    <strong>${ordinal.ordinal}</strong>
+                    ${held ? '<i class="fas fa-lock text-muted ms-1" title="Below partition, not updated"></i>' : ''}
+                </td>
+                <td><small>${pod.name}</small></td>
+                <td>
+                    ${pod.exists
+                        ? `<span class="badge-status"><i class="fas ${getPodStatusIcon(pod.status)} me-1"></i>${pod.status}</span>`
+                        : '<span class="badge bg-danger">Missing</span>'}
+                </td>
+                <td>${pod.ready || '-'}</td>
+                <td>${pod.exists ? pod.restarts : '-'}</td>
+                <td>${revision}</td>
+                <td><small>${pod.node || '-'}</small></td>
+                <td>${volumes}</td>
+                <td>
+                    ${pod.exists ? `
+                    <button class="btn btn-sm btn-outline-danger"
+                            onclick="deletePod('${sts.namespace}', '${pod.name}')"
+                            title="Delete pod (recreated by the controller)">
+                        <i class="fas fa-trash"></i>
+                    </button>` : ''}
+                </td>
+            </tr>
+        `;
+    });
+
+    tbody.innerHTML = html;
+}
+
+// Обновить реплики в открытом окне
+async function refreshOrdinals() {
+    await loadStatefulSets();
+}
+
+// Масштабирование
+async function applyScale() {
+    const { namespace, name } = currentStatefulSet;
+    const replicas = parseInt(document.getElementById('scale-replicas').value);
+    if (isNaN(replicas) || replicas < 0) {
+        showToast('Invalid replicas value', 'warning');
+        return;
+    }
+
+    try {
+        const response = await fetch(`/api/statefulset/scale/${namespace}/${name}?replicas=${replicas}`, {
+            method: 'POST'
+        });
+        const data = await response.json();
+        if (!response.ok) throw new Error(data.error || `HTTP ${response.status}`);
+
+        showToast(data.message || `Scaled to ${replicas}`, 'success');
+        setTimeout(() => loadStatefulSets(), 1000);
+    } catch (error) {
+        showToast(`Failed to scale: ${error.message}`, 'danger');
+    }
+}
+
+// Изменение partition для RollingUpdate
+async function applyPartition() {
+    const { namespace, name } = currentStatefulSet;
+    const partition = parseInt(document.getElementById('partition-input').value);
+    if (isNaN(partition) || partition < 0) {
+        showToast('Invalid partition value', 'warning');
+        return;
+    }
+
+    try {
+        const response = await fetch(`/api/statefulset/partition/${namespace}/${name}?partition=${partition}`, {
+            method: 'POST'
+        });
+        const data = await response.json();
+        if (!response.ok) throw new Error(data.error || `HTTP ${response.status}`);
+
+        showToast(data.message || `Partition set to ${partition}`, 'success');
+        setTimeout(() => loadStatefulSets(), 1000);
+    } catch (error) {
+        showToast(`Failed to set partition: ${error.message}`, 'danger');
+    }
+}
+
+// Удаление пода - контроллер пересоздаст его с тем же ordinal
+async function deletePod(namespace, podName) {
+    if (!confirm(`Delete pod ${podName}? The statefulset controller will recreate it.`)) return;
+
+    try {
+        const response = await fetch(`/api/pod/${namespace}/${podName}`, {
+            method: 'DELETE'
+        });
+        const data = await response.json();
+        if (!response.ok) throw new Error(data.error || `HTTP ${response.status}`);
+
+        showToast(`Pod ${podName} deleted`, 'success');
+        setTimeout(() => loadStatefulSets(), 1000);
+    } catch (error) {
+        showToast(`Failed to delete pod: ${error.message}`, 'danger');
+    }
+}
+
+// Показать модальное окно для рестарта
+function showRestartModal(namespace, name) {
+    currentStatefulSet = { namespace, name };
+
+    document.getElementById('restart-statefulset-name').textContent = name;
+    const modal = new bootstrap.Modal(document.getElementById('restartModal'));
+    modal.show();
+}
+
+// Подтверждение рестарта
+async function confirmRestart() {
+    const { namespace, name } = currentStatefulSet;
+
+    try {
+        const response = await fetch(`/api/statefulset/restart/${namespace}/${name}`, {
+            method: 'POST'
+        });
+        const data = await response.json();
+        if (!response.ok) throw new Error(data.error || `HTTP ${response.status}`);
+
+        showToast(data.message || 'StatefulSet restarted', 'success');
+        bootstrap.Modal.getInstance(document.getElementById('restartModal')).hide();
+        setTimeout(() => loadStatefulSets(), 1000);
+    } catch (error) {
+        showToast(`Failed to restart: ${error.message}`, 'danger');
+    }
+}
+
+// Показать модальное окно для удаления
+function showDeleteModal(namespace, name) {
+    currentStatefulSet = { namespace, name };
+
+    document.getElementById('delete-statefulset-name').textContent = name;
+    const modal = new bootstrap.Modal(document.getElementById('deleteModal'));
+    modal.show();
+}
+
+// Подтверждение удаления
+async function confirmDelete() {
+    const { namespace, name } = currentStatefulSet;
+
+    try {
+        const response = await fetch(`/api/statefulset/${namespace}/${name}`, {
+            method: 'DELETE'
+        });
+        const data = await response.json();
+        if (!response.ok) throw new Error(data.error || `HTTP ${response.status}`);
+
+        showToast(data.message || 'StatefulSet deleted', 'success');
+        bootstrap.Modal.getInstance(document.getElementById('deleteModal')).hide();
+        setTimeout(() => loadStatefulSets(), 1000);
+    } catch (error) {
+        showToast(`Failed to delete: ${error.message}`, 'danger');
+    }
+}
+
+// Показать YAML StatefulSet'а
+async function showConfig(namespace, name) {
+    currentStatefulSet = { namespace, name };
+
+    document.getElementById('config-statefulset-name').textContent = name;
+    setEditMode(false);
+    const modal = new bootstrap.Modal(document.getElementById('configModal'));
+    modal.show();
+
+    await loadYAML();
+}
+
+// Загрузить YAML
+async function loadYAML() {
+    const { namespace, name } = currentStatefulSet;
+
+    try {
+        const response = await fetch(`/api/statefulset/yaml/${namespace}/${name}`);
+        if (!response.ok) throw new Error(`HTTP ${response.status}`);
+
+        const data = await response.json();
+        document.getElementById('yaml-content').textContent = data.yaml;
+        document.getElementById('yaml-editor').value = data.yaml;
+    } catch (error) {
+        document.getElementById('yaml-content').textContent = `Error loading YAML: ${error.message}`;
+    }
+}
+
+// Скопировать YAML в буфер обмена
+async function copyYAML() {
+    const yaml = document.getElementById('yaml-content').textContent;
+    try {
+        await navigator.clipboard.writeText(yaml);
+        showToast('YAML copied to clipboard!', 'success');
+    } catch (err) {
+        showToast('Failed to copy YAML', 'danger');
+    }
+}
+
+// Скачать YAML
+function downloadYAML() {
+    const yaml = document.getElementById('yaml-content').textContent;
+
+    const blob = new Blob([yaml], { type: 'text/yaml' });
+    const url = URL.createObjectURL(blob);
+    const a = document.createElement('a');
+    a.href = url;
+    a.download = `${currentStatefulSet.name}-statefulset.yaml`;
+    document.body.appendChild(a);
+    a.click();
+    document.body.removeChild(a);
+    URL.revokeObjectURL(url);
+}
+
+// Переключение просмотра и редактирования YAML
+function setEditMode(editing) {
+    document.getElementById('yaml-content').style.display = editing ? 'none' : 'block';
+    document.getElementById('yaml-editor').style.display = editing ? 'block' : 'none';
+    document.getElementById('save-yaml-btn').style.display = editing ? 'block' : 'none';
+    document.getElementById('dry-run-yaml-btn').style.display = editing ? 'block' : 'none';
+}
+
+// Редактировать YAML
+function editYAML() {
+    setEditMode(true);
+}
+
+// Сохранить YAML (server-side apply); dryRun - только проверка на сервере
+async function saveYAML(dryRun) {
+    const yaml = document.getElementById('yaml-editor').value;
+    const { namespace, name } = currentStatefulSet;
+
+    try {
+        const response = await fetch(`/api/statefulset/yaml/${namespace}/${name}${dryRun ? '?dryRun=All' : ''}`, {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ yaml })
+        });
+        const data = await response.json();
+        if (!response.ok) throw new Error(data.error || `HTTP ${response.status}`);
+
+        if (dryRun) {
+            showToast('Dry run passed', 'success');
+            return;
+        }
+
+        showToast('StatefulSet configuration updated successfully!', 'success');
+        setEditMode(false);
+        await loadYAML();
+        setTimeout(() => loadStatefulSets(), 1000);
+    } catch (error) {
+        showToast(`Failed to update: ${error.message}`, 'danger');
+    }
+}
+
+// Вспомогательные функции
+function getStatusIcon(status) {
+    switch(status) {
+        case 'ready': return 'fa-check-circle';
+        case 'not-ready': return 'fa-times-circle';
+        case 'progressing': return 'fa-sync-alt';
+        default: return 'fa-question-circle';
+    }
+}
+
+function getPodStatusClass(status) {
+    switch(status) {
+        case 'Running': return 'running';
+        case 'Pending': return 'pending';
+        case 'Failed': return 'failed';
+        case 'Succeeded': return 'succeeded';
+        default: return '';
+    }
+}
+
+function getPodStatusIcon(status) {
+    switch(status) {
+        case 'Running': return 'fa-play-circle';
+        case 'Pending': return 'fa-clock';
+        case 'Failed': return 'fa-exclamation-circle';
+        case 'Succeeded': return 'fa-check-circle';
+        default: return 'fa-question-circle';
+    }
+}
+
+function filterStatefulSets() {
+    renderStatefulSetsTable(allStatefulSets);
+}
+
+function highlightSearch(text, search) {
+    if (!search) return text;
+    const escaped = search.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+    return text.replace(new RegExp(`(${escaped})`, 'gi'), '<span class="highlight">$1</span>');
+}
+
+function escapeHtml(text) {
+    const div = document.createElement('div');
+    div.textContent = text;
+    return div.innerHTML;
+}
+
+function updateLastUpdated() {
+    document.getElementById('last-updated').textContent =
+        `Last updated: ${new Date().toLocaleTimeString()}`;
+}
+
+function showLoading(show) {
+    document.getElementById('loading-spinner').style.display = show ? 'inline-block' : 'none';
+}
+
+function showError(message) {
+    console.error(message);
+    showToast(message, 'danger');
+}
+
+function showToast(message, type = 'info') {
+    const toast = document.createElement('div');
+    toast.className = `toast-alert alert alert-${type} alert-dismissible fade show`;
+    toast.style.cssText = `
+        position: fixed;
+        top: 20px;
+        right: 20px;
+        z-index: 9999;
+        min-width: 300px;
+    `;
+    toast.innerHTML = `
+        ${escapeHtml(message)}
+        <button type="button" class="btn-close" data-bs-dismiss="alert"></button>
+    `;
+
+    document.body.appendChild(toast);
+
+    setTimeout(() => {
+        toast.remove();
+    }, 3000);
+}
+
+function debounce(func, wait) {
+    let timeout;
+    return function executedFunction(...args) {
+        clearTimeout(timeout);
+        timeout = setTimeout(() => func(...args), wait);
+    };
+}